@@ -0,0 +1,193 @@
+package tides
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+const (
+	// Standard atmospheric pressure, in hPa
+	DEFAULT_REFERENCE_PRESSURE = 1013.25
+	// Sea level response to pressure, in meters per hPa (about 1cm per hPa)
+	DEFAULT_INVERSE_BAROMETER_FACTOR = 0.01
+)
+
+type (
+	// Atmospheric pressure at a point in time, in hPa
+	PressureReading struct {
+		Time     time.Time
+		Pressure float64
+	}
+
+	// Wind at a point in time; speed in m/s, direction in degrees true that the wind is blowing from
+	WindReading struct {
+		Time      time.Time
+		Speed     float64
+		Direction float64
+	}
+
+	// Wind setup for winds blowing from within a sector of directions (degrees true, clockwise from From to To),
+	// as meters of setup per (m/s)^2 of wind speed. Negative coefficients give setdown.
+	WindSetupCoefficient struct {
+		From        float64
+		To          float64
+		Coefficient float64
+	}
+
+	// Meteorological adjustments applied to predicted levels, to estimate total water level
+	AtmosphericAdjustment struct {
+		Pressure               []*PressureReading
+		ReferencePressure      float64 // hPa; defaults to DEFAULT_REFERENCE_PRESSURE
+		InverseBarometerFactor float64 // meters per hPa; defaults to DEFAULT_INVERSE_BAROMETER_FACTOR
+		Wind                   []*WindReading
+		WindSetupCoefficients  []*WindSetupCoefficient
+	}
+)
+
+// Applies the inverse barometer correction (and optionally wind setup) to the predicted levels & extrema.
+// Readings are interpolated to each prediction time; before the first or after the last reading, the nearest
+// reading is used. At a subordinate station the adjustment is added after the offsets, at the subordinate times, as
+// the height ratios only apply to the tide.
+func WithAtmosphericAdjustment(adj *AtmosphericAdjustment) PredictionOpt {
+	return func(p *Prediction) {
+		adj.sortReadings()
+		p.Adjustment = adj
+	}
+}
+
+// Returns the adjustment to the level at a time, in meters
+func (a *AtmosphericAdjustment) LevelAt(t time.Time) float64 {
+	return a.InverseBarometerAt(t) + a.WindSetupAt(t)
+}
+
+// Returns the inverse barometer correction at a time, in meters; high pressure lowers the sea surface
+func (a *AtmosphericAdjustment) InverseBarometerAt(t time.Time) float64 {
+	if len(a.Pressure) == 0 {
+		return 0
+	}
+
+	ref := a.ReferencePressure
+	if ref == 0 {
+		ref = DEFAULT_REFERENCE_PRESSURE
+	}
+	factor := a.InverseBarometerFactor
+	if factor == 0 {
+		factor = DEFAULT_INVERSE_BAROMETER_FACTOR
+	}
+
+	i := sort.Search(len(a.Pressure), func(i int) bool { return a.Pressure[i].Time.After(t) })
+	var pressure float64
+	switch {
+	case i == 0:
+		pressure = a.Pressure[0].Pressure
+	case i == len(a.Pressure):
+		pressure = a.Pressure[len(a.Pressure)-1].Pressure
+	default:
+		prev, next := a.Pressure[i-1], a.Pressure[i]
+		frac := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+		pressure = prev.Pressure + frac*(next.Pressure-prev.Pressure)
+	}
+
+	return -(pressure - ref) * factor
+}
+
+// Returns the wind setup at a time, in meters, from the coefficient for the sector the wind is blowing from
+func (a *AtmosphericAdjustment) WindSetupAt(t time.Time) float64 {
+	if len(a.Wind) == 0 || len(a.WindSetupCoefficients) == 0 {
+		return 0
+	}
+
+	i := sort.Search(len(a.Wind), func(i int) bool { return a.Wind[i].Time.After(t) })
+	var speed, direction float64
+	switch {
+	case i == 0:
+		speed, direction = a.Wind[0].Speed, a.Wind[0].Direction
+	case i == len(a.Wind):
+		speed, direction = a.Wind[len(a.Wind)-1].Speed, a.Wind[len(a.Wind)-1].Direction
+	default:
+		// interpolate speed; take the direction of the nearest reading
+		prev, next := a.Wind[i-1], a.Wind[i]
+		frac := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+		speed = prev.Speed + frac*(next.Speed-prev.Speed)
+		direction = prev.Direction
+		if frac > 0.5 {
+			direction = next.Direction
+		}
+	}
+
+	for _, c := range a.WindSetupCoefficients {
+		if c.contains(direction) {
+			return c.Coefficient * speed * speed
+		}
+	}
+
+	return 0
+}
+
+func (c *WindSetupCoefficient) contains(direction float64) bool {
+	width := modulus(c.To-c.From, 360)
+	return modulus(direction-c.From, 360) <= width
+}
+
+// sorts the readings by time, so they can be searched
+func (a *AtmosphericAdjustment) sortReadings() {
+	sort.Slice(a.Pressure, func(i, j int) bool { return a.Pressure[i].Time.Before(a.Pressure[j].Time) })
+	sort.Slice(a.Wind, func(i, j int) bool { return a.Wind[i].Time.Before(a.Wind[j].Time) })
+}
+
+// Loads pressure readings from a CSV file with rows of <time>,<pressure in hPa>. Times may be in any common
+// format, and are interpreted as UTC unless they include a zone. A header row is skipped.
+func LoadPressureFromFile(path string) ([]*PressureReading, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadPressureCSV(f)
+}
+
+// Reads pressure readings from CSV rows of <time>,<pressure in hPa>. See LoadPressureFromFile.
+func ReadPressureCSV(r io.Reader) ([]*PressureReading, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	readings := make([]*PressureReading, 0, len(rows))
+	for i, row := range rows {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("pressure csv line %d: expected <time>,<pressure>", i+1)
+		}
+
+		t, timeErr := dateparse.ParseIn(strings.TrimSpace(row[0]), time.UTC)
+		pressure, pressureErr := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if timeErr != nil || pressureErr != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("pressure csv line %d: could not parse %v", i+1, row)
+		}
+		if math.IsNaN(pressure) {
+			continue
+		}
+
+		readings = append(readings, &PressureReading{Time: t, Pressure: pressure})
+	}
+
+	sort.Slice(readings, func(i, j int) bool { return readings[i].Time.Before(readings[j].Time) })
+	return readings, nil
+}
@@ -0,0 +1,126 @@
+package tides_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverseBarometer(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+
+	// a steady low of 10hPa below the reference raises the water by 10cm
+	adj := &tides.AtmosphericAdjustment{
+		Pressure: []*tides.PressureReading{
+			{Time: start, Pressure: 1003.25},
+			{Time: end, Pressure: 1003.25},
+		},
+	}
+
+	astronomical := har.NewRangePrediction(start, end).PredictExtrema()
+	adjusted := har.NewRangePrediction(start, end, tides.WithAtmosphericAdjustment(adj)).PredictExtrema()
+
+	assert.Equal(t, len(astronomical), len(adjusted))
+	for i := range adjusted {
+		assert.InDelta(t, astronomical[i].Level+0.1, adjusted[i].Level, VAL_TOLERANCE)
+		assert.Equal(t, astronomical[i].Time, adjusted[i].Time)
+	}
+
+	// in feet too
+	feet := har.NewRangePrediction(start, end, tides.WithAtmosphericAdjustment(adj), tides.WithUnits(tides.UNITS_FEET)).PredictExtrema()
+	assert.InDelta(t, adjusted[0].Level/0.3048, feet[0].Level, VAL_TOLERANCE)
+}
+
+func TestSubordinateAdjustment(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	adj := &tides.AtmosphericAdjustment{
+		Pressure: []*tides.PressureReading{
+			{Time: start.Add(-time.Hour * 48), Pressure: 1003.25},
+			{Time: end.Add(time.Hour * 48), Pressure: 1003.25},
+		},
+	}
+
+	// the 10cm is added after the offsets, so it isn't scaled by the height ratios (1.1 & 0.9) or moved in time
+	opts := []tides.PredictionOpt{tides.WithInterval(time.Minute * 30)}
+	astronomical := har.NewRangePrediction(start, end, opts...)
+	adjusted := har.NewRangePrediction(start, end, append(opts, tides.WithAtmosphericAdjustment(adj))...)
+
+	plainResults, adjustedResults := astronomical.Predict(), adjusted.Predict()
+	if !assert.Equal(t, len(plainResults), len(adjustedResults)) {
+		return
+	}
+	for i := range adjustedResults {
+		assert.Equal(t, plainResults[i].Time, adjustedResults[i].Time)
+		assert.InDelta(t, plainResults[i].Level+0.1, adjustedResults[i].Level, 1e-6, adjustedResults[i].Time)
+	}
+
+	plainExtrema, adjustedExtrema := astronomical.PredictExtrema(), adjusted.PredictExtrema()
+	if !assert.Equal(t, len(plainExtrema), len(adjustedExtrema)) {
+		return
+	}
+	for i := range adjustedExtrema {
+		assert.Equal(t, plainExtrema[i].Time, adjustedExtrema[i].Time)
+		assert.InDelta(t, plainExtrema[i].Level+0.1, adjustedExtrema[i].Level, 1e-6, adjustedExtrema[i].Time)
+	}
+
+	// & an instant matches the range
+	at := start.Add(time.Hour*7 + time.Minute*13)
+	plainLevel, err := astronomical.LevelAt(at)
+	assert.NoError(t, err)
+	adjustedLevel, err := adjusted.LevelAt(at)
+	assert.NoError(t, err)
+	assert.InDelta(t, plainLevel+0.1, adjustedLevel, 1e-6)
+}
+
+func TestAdjustmentInterpolation(t *testing.T) {
+	t0 := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	adj := &tides.AtmosphericAdjustment{
+		Pressure: []*tides.PressureReading{
+			{Time: t0, Pressure: 1000},
+			{Time: t0.Add(time.Hour * 2), Pressure: 1020},
+		},
+		ReferencePressure: 1010,
+		Wind: []*tides.WindReading{
+			{Time: t0, Speed: 10, Direction: 200},
+		},
+		WindSetupCoefficients: []*tides.WindSetupCoefficient{
+			{From: 350, To: 90, Coefficient: -0.001},
+			{From: 180, To: 270, Coefficient: 0.002},
+		},
+	}
+
+	assert.InDelta(t, 0.1, adj.InverseBarometerAt(t0.Add(-time.Hour)), 1e-9)
+	assert.InDelta(t, 0.0, adj.InverseBarometerAt(t0.Add(time.Hour)), 1e-9)
+	assert.InDelta(t, -0.1, adj.InverseBarometerAt(t0.Add(time.Hour*3)), 1e-9)
+	assert.InDelta(t, 0.2, adj.WindSetupAt(t0), 1e-9)
+
+	adj.Wind[0].Direction = 10
+	assert.InDelta(t, -0.1, adj.WindSetupAt(t0), 1e-9)
+
+	adj.Wind[0].Direction = 120
+	assert.InDelta(t, 0.0, adj.WindSetupAt(t0), 1e-9)
+}
+
+func TestReadPressureCSV(t *testing.T) {
+	readings, err := tides.ReadPressureCSV(strings.NewReader("time,pressure\n2023-04-10T01:00:00Z,1012.5\n2023-04-10 00:00,1011\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(readings))
+	assert.Equal(t, time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC), readings[0].Time)
+	assert.Equal(t, 1012.5, readings[1].Pressure)
+
+	_, err = tides.ReadPressureCSV(strings.NewReader("2023-04-10T01:00:00Z,1012.5\nnot a time,1000\n"))
+	assert.Error(t, err)
+}
@@ -1,7 +1,9 @@
 package astronomy
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/soniakeys/meeus/v3/julian"
@@ -9,42 +11,84 @@ import (
 
 const (
 	JULIAN_CENTURIES_TO_DEG_PER_HOUR = 1 / (24 * 365.25 * 100)
+
+	// The original mean element series (the default)
+	THEORY_TRUNCATED Theory = "truncated"
+	// The full mean element series from Meeus, Astronomical Algorithms (2nd ed.)
+	THEORY_MEEUS Theory = "meeus"
 )
 
 type (
+	// Selects the series used for the mean lunar & solar elements
+	Theory string
+
 	Astro struct {
 		Time time.Time
+		Settings
+	}
+
+	// Options for the astronomical arguments
+	Settings struct {
+		DeltaT bool   // if set, the mean elements are evaluated in Terrestrial Time (UT + Delta-T)
+		Theory Theory // defaults to THEORY_TRUNCATED
 	}
 )
 
+// Parses a theory name; empty means truncated
+func ParseTheory(s string) (Theory, error) {
+	switch Theory(strings.ToLower(strings.TrimSpace(s))) {
+	case "", THEORY_TRUNCATED:
+		return THEORY_TRUNCATED, nil
+	case THEORY_MEEUS:
+		return THEORY_MEEUS, nil
+	default:
+		return "", fmt.Errorf("unknown astronomy theory: %s", s)
+	}
+}
+
+// Returns an error if the theory is not one of the supported theories
+func (t Theory) Validate() error {
+	switch t {
+	case "", THEORY_TRUNCATED, THEORY_MEEUS:
+		return nil
+	default:
+		return fmt.Errorf("unknown astronomy theory: %s", t)
+	}
+}
+
+// Returns an Astro for a time, with these settings
+func (s Settings) At(t time.Time) *Astro {
+	return &Astro{Time: t, Settings: s}
+}
+
 // Calculates lunar ecliptic longitude and rate of change, variable "s" in Schureman
 func (a *Astro) LunarLongitude() (float64, float64) {
-	return calcValAndSpeed(LUNAR_LONGITUDE, a.Time)
+	return calcValAndSpeed(a.series(LUNAR_LONGITUDE, MEEUS_LUNAR_LONGITUDE), a.dynamicalTime())
 }
 
 // Calculates solar ecliptic longitude and rate of change, variable "h" in Schureman
 func (a *Astro) SolarLongitude() (float64, float64) {
-	return calcValAndSpeed(SOLAR_LONGITUDE, a.Time)
+	return calcValAndSpeed(a.series(SOLAR_LONGITUDE, MEEUS_SOLAR_LONGITUDE), a.dynamicalTime())
 }
 
 // Calculates lunar perigee and rate of change, variable "p" in Schureman
 func (a *Astro) LunarPerigee() (float64, float64) {
-	return calcValAndSpeed(LUNAR_PERIGEE, a.Time)
+	return calcValAndSpeed(a.series(LUNAR_PERIGEE, MEEUS_LUNAR_PERIGEE), a.dynamicalTime())
 }
 
 // Calculates lunar node and rate of change, variable "N" in Schureman
 func (a *Astro) LunarNode() (float64, float64) {
-	return calcValAndSpeed(LUNAR_NODE, a.Time)
+	return calcValAndSpeed(a.series(LUNAR_NODE, MEEUS_LUNAR_NODE), a.dynamicalTime())
 }
 
 // Calculates solar perigee and rate of change, variable "P" in Schureman
 func (a *Astro) SolarPerigee() (float64, float64) {
-	return calcValAndSpeed(SOLAR_PERIGEE, a.Time)
+	return calcValAndSpeed(a.series(SOLAR_PERIGEE, MEEUS_SOLAR_PERIGEE), a.dynamicalTime())
 }
 
 // Calculates terrestrial obliquity and rate of change, variable "omega" in Schureman
 func (a *Astro) TerrestrialObliquity() (float64, float64) {
-	return calcValAndSpeed(TERRESTRIAL_OBLIQUITY, a.Time)
+	return calcValAndSpeed(TERRESTRIAL_OBLIQUITY, a.dynamicalTime())
 }
 
 // Calculates lunar inclination and rate of change, variable "i" in Schureman
@@ -114,6 +158,23 @@ func (a *Astro) P() float64 {
 	return p - (modulus(xi, 360))
 }
 
+// the time at which the mean elements are evaluated
+func (a *Astro) dynamicalTime() time.Time {
+	if a.DeltaT {
+		return a.Time.Add(DeltaT(a.Time))
+	}
+	return a.Time
+}
+
+// picks the coefficients for the configured theory
+func (a *Astro) series(truncated, meeus []float64) []float64 {
+	if a.Theory == THEORY_MEEUS {
+		return meeus
+	}
+	return truncated
+}
+
+// the hour angle of the mean sun is always in universal time
 func (a *Astro) hourAngle() (float64, float64) {
 	v := (JulianDate(a.Time) - math.Floor(JulianDate(a.Time))) * 360.0
 	return v, 15.0
@@ -103,3 +103,256 @@ func TestFixedAngle(t *testing.T) {
 		t.Errorf("FixedAngle() speed = %v, want %v", speed, expectedSpeed)
 	}
 }
+
+func TestDeltaT(t *testing.T) {
+	// published values, in seconds
+	testSet := []struct {
+		Time     time.Time
+		Expected float64
+		Tol      float64
+	}{
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), -2.7, 0.5},
+		{time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC), 29.1, 0.5},
+		{time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 63.8, 0.5},
+		{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 69.4, 3},
+	}
+
+	for _, test := range testSet {
+		assert.InDelta(t, test.Expected, astronomy.DeltaT(test.Time).Seconds(), test.Tol, test.Time.String())
+	}
+
+	// keeps growing into the future
+	assert.Greater(t, astronomy.DeltaT(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)).Seconds(), 150.0)
+}
+
+func TestMeeusTheory(t *testing.T) {
+	a := astronomy.Astro{Time: TEST_DATE, Settings: astronomy.Settings{Theory: astronomy.THEORY_MEEUS}}
+
+	// Meeus example 47.a, 1992 April 12 0h TD
+	ex := astronomy.Astro{Time: time.Date(1992, 4, 12, 0, 0, 0, 0, time.UTC), Settings: astronomy.Settings{Theory: astronomy.THEORY_MEEUS}}
+	s, _ := ex.LunarLongitude()
+	assert.InDelta(t, 134.290182, s, VAL_TOLERANCE)
+
+	// speeds are unchanged to within the precision of the truncated series
+	sMeeus, speedMeeus := a.LunarLongitude()
+	sTrunc, speedTrunc := (&astronomy.Astro{Time: TEST_DATE}).LunarLongitude()
+	assert.InDelta(t, sTrunc, sMeeus, 0.001)
+	assert.InDelta(t, speedTrunc, speedMeeus, SPEED_TOLERANCE)
+}
+
+func TestCompareSettings(t *testing.T) {
+	start := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	settings := astronomy.Settings{DeltaT: true, Theory: astronomy.THEORY_MEEUS}
+
+	diffs := astronomy.CompareSettings(settings, start, end, time.Hour*24*365*10)
+	assert.Equal(t, 21, len(diffs))
+
+	// Delta-T dominates, moving the moon by its motion over a few minutes at most
+	for _, d := range diffs {
+		t.Logf("%d: delta-t %6.1fs, s %+.5f, h %+.5f, p %+.5f, N %+.5f, p1 %+.5f", d.Time.Year(), d.DeltaT.Seconds(),
+			d.LunarLongitude, d.SolarLongitude, d.LunarPerigee, d.LunarNode, d.SolarPerigee)
+		assert.Less(t, d.Max(), 0.05)
+		assert.InDelta(t, d.DeltaT.Hours()*0.549, d.LunarLongitude, 0.001)
+	}
+}
+
+func TestMoonPhases(t *testing.T) {
+	// USNO phases of the moon, January 2024 (UTC)
+	expected := []struct {
+		Type astronomy.MoonEventType
+		Time time.Time
+	}{
+		{astronomy.MOON_LAST_QUARTER, time.Date(2024, 1, 4, 3, 30, 0, 0, time.UTC)},
+		{astronomy.MOON_NEW, time.Date(2024, 1, 11, 11, 57, 0, 0, time.UTC)},
+		{astronomy.MOON_FIRST_QUARTER, time.Date(2024, 1, 18, 3, 53, 0, 0, time.UTC)},
+		{astronomy.MOON_FULL, time.Date(2024, 1, 25, 17, 54, 0, 0, time.UTC)},
+	}
+
+	phases := astronomy.MoonPhases(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, phases, len(expected)) {
+		for i, e := range expected {
+			assert.Equal(t, e.Type, phases[i].Type)
+			assert.LessOrEqual(t, math.Abs(phases[i].Time.Sub(e.Time).Minutes()), 1.0, "%s", e.Type)
+		}
+	}
+
+	// the new moon of the 2024 total solar eclipse
+	eclipse := astronomy.MoonPhases(time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, eclipse, 1) {
+		assert.Equal(t, astronomy.MOON_NEW, eclipse[0].Type)
+		assert.LessOrEqual(t, math.Abs(eclipse[0].Time.Sub(time.Date(2024, 4, 8, 18, 21, 0, 0, time.UTC)).Minutes()), 1.0)
+	}
+	assert.Equal(t, "●", astronomy.MOON_NEW.Symbol())
+	assert.Equal(t, "", astronomy.MOON_PERIGEE.Symbol())
+}
+
+func TestMoonApsides(t *testing.T) {
+	// Meeus example 50.a: apogee 1988 October 7 at 20h30m TD
+	apsides := astronomy.MoonApsides(time.Date(1988, 10, 7, 0, 0, 0, 0, time.UTC), time.Date(1988, 10, 8, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, apsides, 1) {
+		td := time.Date(1988, 10, 7, 20, 30, 0, 0, time.UTC)
+		assert.Equal(t, astronomy.MOON_APOGEE, apsides[0].Type)
+		assert.LessOrEqual(t, math.Abs(apsides[0].Time.Sub(td.Add(-astronomy.DeltaT(td))).Minutes()), 1.0)
+	}
+
+	// perigee 2024 January 13 10:35 UTC, 362,267 km
+	apsides = astronomy.MoonApsides(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if assert.Len(t, apsides, 3) {
+		assert.Equal(t, []astronomy.MoonEventType{astronomy.MOON_APOGEE, astronomy.MOON_PERIGEE, astronomy.MOON_APOGEE},
+			[]astronomy.MoonEventType{apsides[0].Type, apsides[1].Type, apsides[2].Type})
+		assert.LessOrEqual(t, math.Abs(apsides[1].Time.Sub(time.Date(2024, 1, 13, 10, 35, 0, 0, time.UTC)).Minutes()), 1.0)
+		assert.InDelta(t, 362267, apsides[1].Value, 10)
+	}
+}
+
+func TestMoonDeclinationEvents(t *testing.T) {
+	// Meeus example 52.a: maximum northern declination 1988 December 22 at 20h01m TD, 28.1562°
+	events := astronomy.MoonDeclinationEvents(time.Date(1988, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(1989, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var north *astronomy.MoonEvent
+	for _, e := range events {
+		if e.Type == astronomy.MOON_MAX_NORTH_DECLINATION {
+			north = e
+		}
+	}
+	if assert.NotNil(t, north) {
+		td := time.Date(1988, 12, 22, 20, 1, 0, 0, time.UTC)
+		assert.LessOrEqual(t, math.Abs(north.Time.Sub(td.Add(-astronomy.DeltaT(td))).Minutes()), 1.0)
+		assert.InDelta(t, 28.1562, north.Value, 0.001)
+	}
+
+	// extrema & crossings alternate, and the moon is on the equator at each crossing
+	order := map[astronomy.MoonEventType]astronomy.MoonEventType{
+		astronomy.MOON_MAX_NORTH_DECLINATION: astronomy.MOON_EQUATOR_SOUTHBOUND,
+		astronomy.MOON_EQUATOR_SOUTHBOUND:    astronomy.MOON_MAX_SOUTH_DECLINATION,
+		astronomy.MOON_MAX_SOUTH_DECLINATION: astronomy.MOON_EQUATOR_NORTHBOUND,
+		astronomy.MOON_EQUATOR_NORTHBOUND:    astronomy.MOON_MAX_NORTH_DECLINATION,
+	}
+	assert.Len(t, events, 5)
+	for i, e := range events {
+		if i > 0 {
+			assert.Equal(t, order[events[i-1].Type], e.Type)
+		}
+		if e.Type == astronomy.MOON_EQUATOR_NORTHBOUND || e.Type == astronomy.MOON_EQUATOR_SOUTHBOUND {
+			// the declination changes by about 0.25° per hour at the equator
+			assert.InDelta(t, 0, astronomy.MoonDeclination(e.Time), 0.25/60)
+		}
+	}
+}
+
+func TestMoonEvents(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := astronomy.MoonEvents(start, end)
+
+	counts := map[astronomy.MoonEventType]int{}
+	for i, e := range events {
+		counts[e.Type]++
+		assert.False(t, e.Time.Before(start) || !e.Time.Before(end))
+		if i > 0 {
+			assert.False(t, e.Time.Before(events[i-1].Time))
+		}
+	}
+
+	// 2024 has 13 new moons (including two in December) and 12 full moons
+	assert.Equal(t, 13, counts[astronomy.MOON_NEW])
+	assert.Equal(t, 12, counts[astronomy.MOON_FULL])
+	assert.Equal(t, 13, counts[astronomy.MOON_PERIGEE])
+	assert.Equal(t, 14, counts[astronomy.MOON_APOGEE])
+}
+
+func TestDay(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// USNO, Seattle WA, 2024 June 21 (PDT)
+	day := astronomy.Day(time.Date(2024, 6, 21, 12, 0, 0, 0, loc), 47.6026, -122.3393)
+	expected := []struct {
+		Name     string
+		Time     *time.Time
+		Expected time.Time
+	}{
+		{"civil dawn", day.CivilDawn, time.Date(2024, 6, 21, 4, 31, 0, 0, loc)},
+		{"sunrise", day.Sunrise, time.Date(2024, 6, 21, 5, 11, 0, 0, loc)},
+		{"sunset", day.Sunset, time.Date(2024, 6, 21, 21, 11, 0, 0, loc)},
+		{"civil dusk", day.CivilDusk, time.Date(2024, 6, 21, 21, 52, 0, 0, loc)},
+	}
+	for _, e := range expected {
+		if assert.NotNil(t, e.Time, e.Name) {
+			assert.LessOrEqual(t, math.Abs(e.Time.Sub(e.Expected).Minutes()), 1.0, e.Name)
+		}
+	}
+
+	// the moon's center is just above the horizon when it rises & sets, with refraction & parallax
+	for _, mt := range []*time.Time{day.Moonrise, day.Moonset} {
+		if assert.NotNil(t, mt) {
+			assert.InDelta(t, 0.125, astronomy.MoonAltitude(*mt, 47.6026, -122.3393), 0.05)
+		}
+	}
+
+	// full moon on 2024 January 25 at 17:54 UTC
+	day = astronomy.Day(time.Date(2024, 1, 25, 0, 0, 0, 0, loc), 47.6026, -122.3393)
+	assert.InDelta(t, 1, day.MoonIllumination, 0.001)
+	assert.False(t, day.MoonWaxing)
+	// first quarter on 2024 January 18 at 03:53 UTC, 16 hours before local noon
+	day = astronomy.Day(time.Date(2024, 1, 18, 0, 0, 0, 0, loc), 47.6026, -122.3393)
+	assert.Greater(t, day.MoonIllumination, 0.5)
+	assert.Less(t, day.MoonIllumination, 0.65)
+	assert.True(t, day.MoonWaxing)
+
+	// midnight sun in Tromsø
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	day = astronomy.Day(time.Date(2024, 6, 21, 0, 0, 0, 0, oslo), 69.65, 18.96)
+	assert.Nil(t, day.Sunrise)
+	assert.Nil(t, day.Sunset)
+}
+
+func TestSolunar(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	lat, lon := 47.6026, -122.3393
+
+	for d := 0; d < 30; d++ {
+		day := astronomy.Day(time.Date(2024, 1, 1+d, 0, 0, 0, 0, loc), lat, lon)
+
+		// the moon culminates at upper transit, at 90° less its distance from the zenith
+		if day.MoonUpperTransit != nil {
+			upper := *day.MoonUpperTransit
+			alt := astronomy.MoonAltitude(upper, lat, lon)
+			assert.InDelta(t, 90-math.Abs(lat-astronomy.MoonDeclination(upper)), alt, 0.05)
+			assert.Greater(t, alt, astronomy.MoonAltitude(upper.Add(-15*time.Minute), lat, lon))
+			assert.Greater(t, alt, astronomy.MoonAltitude(upper.Add(15*time.Minute), lat, lon))
+		}
+		if day.MoonLowerTransit != nil {
+			lower := *day.MoonLowerTransit
+			alt := astronomy.MoonAltitude(lower, lat, lon)
+			assert.Less(t, alt, astronomy.MoonAltitude(lower.Add(-15*time.Minute), lat, lon))
+			assert.Less(t, alt, astronomy.MoonAltitude(lower.Add(15*time.Minute), lat, lon))
+		}
+
+		// two major & up to two minor periods, in order
+		periods := day.SolunarPeriods()
+		counts := map[astronomy.SolunarPeriodType]int{}
+		for i, p := range periods {
+			counts[p.Type]++
+			if p.Type == astronomy.SOLUNAR_MAJOR {
+				assert.Equal(t, astronomy.SOLUNAR_MAJOR_PERIOD, p.End.Sub(p.Start))
+			} else {
+				assert.Equal(t, astronomy.SOLUNAR_MINOR_PERIOD, p.End.Sub(p.Start))
+			}
+			if i > 0 {
+				assert.False(t, p.Center.Before(periods[i-1].Center))
+			}
+		}
+		assert.True(t, counts[astronomy.SOLUNAR_MAJOR] >= 1 && counts[astronomy.SOLUNAR_MAJOR] <= 2)
+		assert.LessOrEqual(t, counts[astronomy.SOLUNAR_MINOR], 2)
+	}
+}
@@ -2,6 +2,7 @@ package astronomy
 
 import "math"
 
+// Mean elements used by THEORY_TRUNCATED
 var (
 	TERRESTRIAL_OBLIQUITY []float64
 	SOLAR_PERIGEE         = []float64{280.46645 - 357.5291, 36000.76932 - 35999.0503, 0.0003032 + 0.0001559, 0.00000048}
@@ -12,6 +13,17 @@ var (
 	LUNAR_PERIGEE         = []float64{83.353243, 4069.0137111, -0.0103238, -1 / 80053.0, 1 / 18999000.0}
 )
 
+// Mean elements from Meeus, Astronomical Algorithms (2nd ed.), equations 25.2, 25.3 & 47.1-47.7, used by THEORY_MEEUS.
+// The moonposition package provides the node & perigee, but its cubic & quartic terms are lost to integer constant
+// division, so the full series are given here.
+var (
+	MEEUS_SOLAR_LONGITUDE = []float64{280.46646, 36000.76983, 0.0003032}
+	MEEUS_SOLAR_PERIGEE   = []float64{280.46646 - 357.52911, 36000.76983 - 35999.05029, 0.0003032 + 0.0001537}
+	MEEUS_LUNAR_LONGITUDE = []float64{218.3164477, 481267.88123421, -0.0015786, 1 / 538841.0, -1 / 65194000.0}
+	MEEUS_LUNAR_NODE      = []float64{125.0445479, -1934.1362891, 0.0020754, 1 / 467441.0, -1 / 60616000.0}
+	MEEUS_LUNAR_PERIGEE   = []float64{83.3532465, 4069.0137287, -0.0103200, -1 / 80053.0, 1 / 18999000.0}
+)
+
 func init() {
 	rawValues := []struct {
 		degrees float64
@@ -0,0 +1,47 @@
+package astronomy
+
+import (
+	"math"
+	"time"
+)
+
+// The difference in the mean elements between two settings at a time, in degrees
+type ArgumentDifference struct {
+	Time           time.Time
+	DeltaT         time.Duration
+	LunarLongitude float64
+	SolarLongitude float64
+	LunarPerigee   float64
+	LunarNode      float64
+	SolarPerigee   float64
+}
+
+// Compares the mean elements calculated with the given settings against the defaults, at each step from start to end
+func CompareSettings(s Settings, start, end time.Time, step time.Duration) []*ArgumentDifference {
+	diffs := make([]*ArgumentDifference, 0)
+	for t := start; !t.After(end); t = t.Add(step) {
+		a, b := s.At(t), &Astro{Time: t}
+
+		d := &ArgumentDifference{Time: t, DeltaT: DeltaT(t)}
+		d.LunarLongitude = angleDiff(a.LunarLongitude, b.LunarLongitude)
+		d.SolarLongitude = angleDiff(a.SolarLongitude, b.SolarLongitude)
+		d.LunarPerigee = angleDiff(a.LunarPerigee, b.LunarPerigee)
+		d.LunarNode = angleDiff(a.LunarNode, b.LunarNode)
+		d.SolarPerigee = angleDiff(a.SolarPerigee, b.SolarPerigee)
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// the difference between two angles, in the range [-180, 180)
+func angleDiff(a, b func() (float64, float64)) float64 {
+	x, _ := a()
+	y, _ := b()
+	return modulus(x-y+180, 360) - 180
+}
+
+// Returns the largest absolute difference of any element
+func (d *ArgumentDifference) Max() float64 {
+	return math.Max(math.Max(math.Max(math.Abs(d.LunarLongitude), math.Abs(d.SolarLongitude)),
+		math.Max(math.Abs(d.LunarPerigee), math.Abs(d.LunarNode))), math.Abs(d.SolarPerigee))
+}
@@ -0,0 +1,43 @@
+package astronomy
+
+import (
+	"math"
+	"time"
+
+	"github.com/soniakeys/meeus/v3/deltat"
+	"github.com/soniakeys/meeus/v3/julian"
+)
+
+// Returns Delta-T (TT - UT) at a time. Uses the Meeus table 10.A from 1620 to 2004, and the Espenak & Meeus (2006)
+// polynomials outside of that range.
+func DeltaT(t time.Time) time.Duration {
+	year := decimalYear(t)
+
+	var seconds float64
+	switch {
+	case year < 948:
+		seconds = float64(deltat.PolyBefore948(year))
+	case year < 1620:
+		seconds = float64(deltat.Poly948to1600(year))
+	case year < 2005:
+		seconds = float64(deltat.Interp10A(julian.TimeToJD(t)))
+	case year < 2050:
+		y := year - 2000
+		seconds = 62.92 + 0.32217*y + 0.005589*y*y
+	case year < 2150:
+		u := (year - 1820) / 100
+		seconds = -20 + 32*u*u - 0.5628*(2150-year)
+	default:
+		u := (year - 1820) / 100
+		seconds = -20 + 32*u*u
+	}
+
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
+
+func decimalYear(t time.Time) float64 {
+	t = t.UTC()
+	start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	return float64(t.Year()) + float64(t.Sub(start))/float64(end.Sub(start))
+}
@@ -0,0 +1,209 @@
+package astronomy
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/soniakeys/meeus/v3/apsis"
+	"github.com/soniakeys/meeus/v3/julian"
+	"github.com/soniakeys/meeus/v3/moonmaxdec"
+	"github.com/soniakeys/meeus/v3/moonphase"
+	"github.com/soniakeys/unit"
+)
+
+const (
+	MOON_NEW           MoonEventType = "new"
+	MOON_FIRST_QUARTER MoonEventType = "first_quarter"
+	MOON_FULL          MoonEventType = "full"
+	MOON_LAST_QUARTER  MoonEventType = "last_quarter"
+
+	MOON_PERIGEE MoonEventType = "perigee"
+	MOON_APOGEE  MoonEventType = "apogee"
+
+	MOON_MAX_NORTH_DECLINATION MoonEventType = "max_north_declination"
+	MOON_MAX_SOUTH_DECLINATION MoonEventType = "max_south_declination"
+	MOON_EQUATOR_NORTHBOUND    MoonEventType = "equator_northbound" // crossing the equator, heading north
+	MOON_EQUATOR_SOUTHBOUND    MoonEventType = "equator_southbound" // crossing the equator, heading south
+
+	SYNODIC_MONTH     = 29.530588861 // days
+	ANOMALISTIC_MONTH = 27.554549886 // days
+	TROPICAL_MONTH    = 27.321582247 // days
+
+	EARTH_EQUATORIAL_RADIUS = 6378.14 // km
+)
+
+type (
+	MoonEventType string
+
+	MoonEvent struct {
+		Time  time.Time // UTC
+		Type  MoonEventType
+		Value float64 // distance in km for perigee & apogee; declination in degrees for declination events
+	}
+
+	// finds the event of a type nearest a decimal year, returning its jde and value
+	moonEventFunc func(year float64) (jde, value float64)
+)
+
+// Returns the symbol conventionally used in tide tables for a phase of the moon, or an empty string for other events
+func (t MoonEventType) Symbol() string {
+	switch t {
+	case MOON_NEW:
+		return "●"
+	case MOON_FIRST_QUARTER:
+		return "◐"
+	case MOON_FULL:
+		return "○"
+	case MOON_LAST_QUARTER:
+		return "◑"
+	default:
+		return ""
+	}
+}
+
+// Calculates the times of new moon, first quarter, full moon and last quarter within a range, using Meeus chapter 49
+func MoonPhases(start, end time.Time) []*MoonEvent {
+	events := make([]*MoonEvent, 0)
+	phases := map[MoonEventType]func(float64) float64{
+		MOON_NEW:           moonphase.New,
+		MOON_FIRST_QUARTER: moonphase.First,
+		MOON_FULL:          moonphase.Full,
+		MOON_LAST_QUARTER:  moonphase.Last,
+	}
+	for eventType, phase := range phases {
+		phase := phase
+		events = append(events, findMoonEvents(start, end, eventType, SYNODIC_MONTH, func(y float64) (float64, float64) {
+			return phase(y), 0
+		})...)
+	}
+	return sortMoonEvents(events)
+}
+
+// Calculates the times of lunar perigee & apogee within a range, with the distance of the moon, using Meeus chapter 50
+func MoonApsides(start, end time.Time) []*MoonEvent {
+	events := findMoonEvents(start, end, MOON_PERIGEE, ANOMALISTIC_MONTH, func(y float64) (float64, float64) {
+		return apsis.Perigee(y), parallaxToDistance(apsis.PerigeeParallax(y))
+	})
+	events = append(events, findMoonEvents(start, end, MOON_APOGEE, ANOMALISTIC_MONTH, func(y float64) (float64, float64) {
+		return apsis.Apogee(y), parallaxToDistance(apsis.ApogeeParallax(y))
+	})...)
+	return sortMoonEvents(events)
+}
+
+// Calculates the times of maximum north & south lunar declination (Meeus chapter 52), and the equatorial crossings
+// between them, within a range
+func MoonDeclinationEvents(start, end time.Time) []*MoonEvent {
+	// extend the range so that the crossings at either end are bracketed by extrema
+	margin := time.Duration(TROPICAL_MONTH / 2 * 24 * float64(time.Hour))
+	extrema := findMoonEvents(start.Add(-margin), end.Add(margin), MOON_MAX_NORTH_DECLINATION, TROPICAL_MONTH, func(y float64) (float64, float64) {
+		jde, dec := moonmaxdec.North(y)
+		return jde, dec.Deg()
+	})
+	extrema = append(extrema, findMoonEvents(start.Add(-margin), end.Add(margin), MOON_MAX_SOUTH_DECLINATION, TROPICAL_MONTH, func(y float64) (float64, float64) {
+		jde, dec := moonmaxdec.South(y)
+		return jde, dec.Deg()
+	})...)
+	extrema = sortMoonEvents(extrema)
+
+	events := make([]*MoonEvent, 0)
+	for i, ex := range extrema {
+		if i > 0 {
+			prev := extrema[i-1]
+			crossingType := MOON_EQUATOR_SOUTHBOUND
+			if prev.Type == MOON_MAX_SOUTH_DECLINATION {
+				crossingType = MOON_EQUATOR_NORTHBOUND
+			}
+			crossing := &MoonEvent{
+				Time: equatorCrossing(prev.Time, ex.Time),
+				Type: crossingType,
+			}
+			if inRange(crossing.Time, start, end) {
+				events = append(events, crossing)
+			}
+		}
+		if inRange(ex.Time, start, end) {
+			events = append(events, ex)
+		}
+	}
+	return events
+}
+
+// Calculates all of the moon events within a range, in time order
+func MoonEvents(start, end time.Time) []*MoonEvent {
+	events := MoonPhases(start, end)
+	events = append(events, MoonApsides(start, end)...)
+	events = append(events, MoonDeclinationEvents(start, end)...)
+	return sortMoonEvents(events)
+}
+
+// Calculates the apparent declination of the moon at a time, in degrees
+func MoonDeclination(t time.Time) float64 {
+	return moonDeclination(utToJDE(t)).Deg()
+}
+
+// steps through the range by the mean period of the event, collecting each distinct event within the range
+func findMoonEvents(start, end time.Time, eventType MoonEventType, periodDays float64, fn moonEventFunc) []*MoonEvent {
+	events := make([]*MoonEvent, 0)
+	step := periodDays / 365.25
+
+	var last time.Time
+	for y := decimalYear(start) - step; y <= decimalYear(end)+step; y += step {
+		jde, value := fn(y)
+		t := jdeToUT(jde)
+		if !last.IsZero() && math.Abs(t.Sub(last).Hours()) < periodDays*12 {
+			continue
+		}
+		last = t
+		if inRange(t, start, end) {
+			events = append(events, &MoonEvent{Time: t, Type: eventType, Value: value})
+		}
+	}
+	return events
+}
+
+// bisects for the time between two declination extrema at which the declination is zero, to the second
+func equatorCrossing(a, b time.Time) time.Time {
+	lo, hi := utToJDE(a), utToJDE(b)
+	loSign := moonDeclination(lo) > 0
+	for hi-lo > 1.0/86400 {
+		mid := (lo + hi) / 2
+		if (moonDeclination(mid) > 0) == loSign {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return jdeToUT((lo + hi) / 2)
+}
+
+// the apparent declination of the moon, including nutation
+func moonDeclination(jde float64) unit.Angle {
+	_, δ, _ := moonEquatorial(jde)
+	return δ
+}
+
+func parallaxToDistance(parallax unit.Angle) float64 {
+	return EARTH_EQUATORIAL_RADIUS / math.Sin(parallax.Rad())
+}
+
+// converts a julian ephemeris day (terrestrial time) to a UTC time, rounded to the second
+func jdeToUT(jde float64) time.Time {
+	tt := julian.JDToTime(jde)
+	return tt.Add(-DeltaT(tt)).Round(time.Second)
+}
+
+// converts a time to a julian ephemeris day (terrestrial time)
+func utToJDE(t time.Time) float64 {
+	t = t.UTC()
+	return julian.TimeToJD(t.Add(DeltaT(t)))
+}
+
+func inRange(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+func sortMoonEvents(events []*MoonEvent) []*MoonEvent {
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
@@ -0,0 +1,246 @@
+package astronomy
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/soniakeys/meeus/v3/base"
+	"github.com/soniakeys/meeus/v3/coord"
+	"github.com/soniakeys/meeus/v3/julian"
+	"github.com/soniakeys/meeus/v3/moonillum"
+	"github.com/soniakeys/meeus/v3/moonposition"
+	"github.com/soniakeys/meeus/v3/nutation"
+	"github.com/soniakeys/meeus/v3/sidereal"
+	"github.com/soniakeys/meeus/v3/solar"
+	"github.com/soniakeys/unit"
+)
+
+const (
+	SUN_STANDARD_ALTITUDE   = -0.8333 // degrees; refraction & semidiameter, Meeus chapter 15
+	CIVIL_TWILIGHT_ALTITUDE = -6.0    // degrees
+
+	// solunar major periods are centered on the moon's transits, & minor periods on moonrise & moonset
+	SOLUNAR_MAJOR_PERIOD = 2 * time.Hour
+	SOLUNAR_MINOR_PERIOD = time.Hour
+
+	SOLUNAR_MAJOR SolunarPeriodType = "major"
+	SOLUNAR_MINOR SolunarPeriodType = "minor"
+
+	riseSetStep      = 10 * time.Minute
+	riseSetPrecision = time.Second
+)
+
+type (
+	// Sun & moon events for a calendar day at a location; times are nil when the event does not occur that day
+	// (e.g. the moon rises about 50 minutes later each day, so skips one day a month)
+	DayEvents struct {
+		Start     time.Time // start of the day, in the requested location
+		CivilDawn *time.Time
+		Sunrise   *time.Time
+		Sunset    *time.Time
+		CivilDusk *time.Time
+		Moonrise  *time.Time
+		Moonset   *time.Time
+
+		MoonUpperTransit *time.Time // moon crosses the meridian overhead
+		MoonLowerTransit *time.Time // moon crosses the meridian underfoot
+
+		MoonIllumination float64 // illuminated fraction of the moon's disk at local noon, 0-1
+		MoonWaxing       bool    // true between new & full moon
+	}
+
+	SolunarPeriodType string
+
+	// A crossing of the moon over the meridian
+	MoonTransit struct {
+		Time  time.Time
+		Upper bool // overhead if true, otherwise underfoot
+	}
+
+	crossing struct {
+		time   time.Time
+		rising bool
+	}
+
+	// A period of expected fish & game activity, centered on a lunar event
+	SolunarPeriod struct {
+		Type   SolunarPeriodType
+		Start  time.Time
+		End    time.Time
+		Center time.Time
+	}
+)
+
+// Calculates sunrise, sunset, civil twilight, moonrise, moonset and moon illumination for the calendar day containing
+// t, in t's location. Latitude & longitude are in degrees, north & east positive.
+func Day(t time.Time, lat, lon float64) *DayEvents {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	end := start.AddDate(0, 0, 1)
+
+	sun := func(t time.Time) float64 { return SunAltitude(t, lat, lon) }
+	moon := func(t time.Time) float64 {
+		return MoonAltitude(t, lat, lon) - moonStandardAltitude(t)
+	}
+	civil := func(t time.Time) float64 { return sun(t) - CIVIL_TWILIGHT_ALTITUDE }
+	standard := func(t time.Time) float64 { return sun(t) - SUN_STANDARD_ALTITUDE }
+
+	d := &DayEvents{Start: start}
+	d.CivilDawn, d.CivilDusk = riseAndSet(civil, start, end)
+	d.Sunrise, d.Sunset = riseAndSet(standard, start, end)
+	d.Moonrise, d.Moonset = riseAndSet(moon, start, end)
+	d.MoonUpperTransit, d.MoonLowerTransit = MoonTransits(start, end, lon)
+
+	noon := start.Add(end.Sub(start) / 2)
+	d.MoonIllumination = MoonIllumination(noon)
+	d.MoonWaxing = moonWaxing(noon)
+	return d
+}
+
+// Returns the solunar periods for the day, in time order: major periods around the moon's upper & lower transits,
+// and minor periods around moonrise & moonset
+func (d *DayEvents) SolunarPeriods() []*SolunarPeriod {
+	periods := make([]*SolunarPeriod, 0)
+	add := func(t *time.Time, periodType SolunarPeriodType, length time.Duration) {
+		if t == nil {
+			return
+		}
+		periods = append(periods, &SolunarPeriod{
+			Type:   periodType,
+			Start:  t.Add(-length / 2),
+			End:    t.Add(length / 2),
+			Center: *t,
+		})
+	}
+	add(d.MoonUpperTransit, SOLUNAR_MAJOR, SOLUNAR_MAJOR_PERIOD)
+	add(d.MoonLowerTransit, SOLUNAR_MAJOR, SOLUNAR_MAJOR_PERIOD)
+	add(d.Moonrise, SOLUNAR_MINOR, SOLUNAR_MINOR_PERIOD)
+	add(d.Moonset, SOLUNAR_MINOR, SOLUNAR_MINOR_PERIOD)
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Center.Before(periods[j].Center) })
+	return periods
+}
+
+// Calculates the first upper & lower transits of the moon across the meridian of a longitude within a range; either
+// is nil if it does not occur (the moon transits about 50 minutes later each day)
+func MoonTransits(start, end time.Time, lon float64) (upper, lower *time.Time) {
+	// the sine of the hour angle rises through zero at upper transit, and falls through zero at lower transit
+	return riseAndSet(func(t time.Time) float64 {
+		return math.Sin(moonHourAngle(t, lon))
+	}, start, end)
+}
+
+// Calculates every upper & lower transit of the moon across the meridian of a longitude within a range, in time order
+func MoonTransitsBetween(start, end time.Time, lon float64) []*MoonTransit {
+	transits := make([]*MoonTransit, 0)
+	for _, c := range zeroCrossings(func(t time.Time) float64 {
+		return math.Sin(moonHourAngle(t, lon))
+	}, start, end) {
+		transits = append(transits, &MoonTransit{Time: c.time, Upper: c.rising})
+	}
+	return transits
+}
+
+// Calculates the geometric altitude of the sun's center at a time & location, in degrees
+func SunAltitude(t time.Time, lat, lon float64) float64 {
+	α, δ := solar.ApparentEquatorial(utToJDE(t))
+	return altitude(t, lat, lon, α, δ)
+}
+
+// Calculates the geocentric altitude of the moon's center at a time & location, in degrees
+func MoonAltitude(t time.Time, lat, lon float64) float64 {
+	α, δ, _ := moonEquatorial(utToJDE(t))
+	return altitude(t, lat, lon, α, δ)
+}
+
+// Calculates the illuminated fraction of the moon's disk at a time, 0-1 (Meeus chapter 48)
+func MoonIllumination(t time.Time) float64 {
+	i := moonillum.PhaseAngle3(utToJDE(t))
+	return (1 + math.Cos(i.Rad())) / 2
+}
+
+// finds the first upward & downward zero crossings of f within a range
+func riseAndSet(f func(time.Time) float64, start, end time.Time) (rise, set *time.Time) {
+	for _, c := range zeroCrossings(f, start, end) {
+		c := c
+		if c.rising && rise == nil {
+			rise = &c.time
+		} else if !c.rising && set == nil {
+			set = &c.time
+		}
+	}
+	return rise, set
+}
+
+// finds every zero crossing of f within a range, by stepping & then bisecting
+func zeroCrossings(f func(time.Time) float64, start, end time.Time) []crossing {
+	crossings := make([]crossing, 0)
+	prevT, prevV := start, f(start)
+	for t := start.Add(riseSetStep); !t.After(end); t = t.Add(riseSetStep) {
+		v := f(t)
+		if (prevV < 0) != (v < 0) {
+			crossings = append(crossings, crossing{time: bisectZero(f, prevT, t), rising: v >= 0})
+		}
+		prevT, prevV = t, v
+	}
+	return crossings
+}
+
+// finds the time within [a, b] at which f changes sign, to the second
+func bisectZero(f func(time.Time) float64, a, b time.Time) time.Time {
+	aPositive := f(a) >= 0
+	for b.Sub(a) > riseSetPrecision {
+		mid := a.Add(b.Sub(a) / 2)
+		if (f(mid) >= 0) == aPositive {
+			a = mid
+		} else {
+			b = mid
+		}
+	}
+	return a.Add(b.Sub(a) / 2).Round(time.Second)
+}
+
+// the altitude of a body with apparent equatorial coordinates α & δ, in degrees (Meeus formula 13.6)
+func altitude(t time.Time, lat, lon float64, α unit.RA, δ unit.Angle) float64 {
+	H := localHourAngle(t, lon, α)
+	φ := lat * DEG_TO_RAD
+	sinh := math.Sin(φ)*math.Sin(δ.Rad()) + math.Cos(φ)*math.Cos(δ.Rad())*math.Cos(H)
+	return math.Asin(sinh) * RAD_TO_DEG
+}
+
+// the local hour angle of a body with apparent right ascension α, in radians
+func localHourAngle(t time.Time, lon float64, α unit.RA) float64 {
+	θ0 := sidereal.Apparent(julian.TimeToJD(t.UTC())).Angle()
+	return θ0.Rad() + lon*DEG_TO_RAD - α.Rad()
+}
+
+// the local hour angle of the moon, in radians
+func moonHourAngle(t time.Time, lon float64) float64 {
+	α, _, _ := moonEquatorial(utToJDE(t))
+	return localHourAngle(t, lon, α)
+}
+
+// the apparent equatorial coordinates of the moon, and its distance in km
+func moonEquatorial(jde float64) (unit.RA, unit.Angle, float64) {
+	λ, β, Δ := moonposition.Position(jde)
+	Δψ, Δε := nutation.Nutation(jde)
+	ε := nutation.MeanObliquity(jde) + Δε
+	sε, cε := ε.Sincos()
+	α, δ := coord.EclToEq(λ+Δψ, β, sε, cε)
+	return α, δ, Δ
+}
+
+// the altitude of the moon's center at rising & setting, which varies with its parallax (Meeus chapter 15)
+func moonStandardAltitude(t time.Time) float64 {
+	_, _, Δ := moonEquatorial(utToJDE(t))
+	parallax := math.Asin(EARTH_EQUATORIAL_RADIUS/Δ) * RAD_TO_DEG
+	return 0.7275*parallax - 0.5667
+}
+
+// the moon is waxing when its elongation east of the sun is between 0° & 180°
+func moonWaxing(t time.Time) bool {
+	jde := utToJDE(t)
+	λ, _, _ := moonposition.Position(jde)
+	λ0 := solar.ApparentLongitude(base.J2000Century(jde))
+	return modulus((λ-λ0).Deg(), 360) < 180
+}
@@ -0,0 +1,75 @@
+package tides
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+type (
+	// Equilibrium arguments & node factors for each constituent in the catalog over a range of years, laid out
+	// like NOAA's Tables 14 & 15 (from Schureman)
+	AstronomicalTable struct {
+		Years       []int
+		NodeFactors NodeFactorMethod
+		Rows        []*AstronomicalTableRow
+	}
+
+	AstronomicalTableRow struct {
+		Name  string
+		Speed float64 // degrees per hour
+		// V0 + u in degrees, for the meridian of Greenwich, with V0 at the start of each year and u at the middle
+		EquilibriumArguments []float64
+		// f at the middle of each year
+		FormFactors []float64
+	}
+)
+
+// Calculates the equilibrium arguments (V0 + u) and node factors (f) for the years from start to end, inclusive
+func NewAstronomicalTable(startYear, endYear int, method NodeFactorMethod, settings astronomy.Settings) (*AstronomicalTable, error) {
+	if endYear < startYear {
+		return nil, fmt.Errorf("end year %d is before start year %d", endYear, startYear)
+	}
+
+	table := &AstronomicalTable{
+		Years:       make([]int, 0, endYear-startYear+1),
+		NodeFactors: method,
+		Rows:        make([]*AstronomicalTableRow, 0, len(CONSTITUENT_NAMES)),
+	}
+	for year := startYear; year <= endYear; year++ {
+		table.Years = append(table.Years, year)
+	}
+
+	for _, name := range CONSTITUENT_NAMES {
+		model := GetConstituentModelForName(name)
+		u, f := nodeFactorFuncs(model, method)
+
+		row := &AstronomicalTableRow{
+			Name:                 name,
+			Speed:                model.Speed(settings.At(time.Date(startYear, 1, 1, 0, 0, 0, 0, time.UTC))),
+			EquilibriumArguments: make([]float64, 0, len(table.Years)),
+			FormFactors:          make([]float64, 0, len(table.Years)),
+		}
+		for _, year := range table.Years {
+			yearStart := settings.At(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+			midYear := settings.At(NODAL_YEARLY.evaluationTime(yearStart.Time))
+
+			row.EquilibriumArguments = append(row.EquilibriumArguments, modulus(model.Value(yearStart)+u(midYear), 360))
+			row.FormFactors = append(row.FormFactors, f(midYear))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table, nil
+}
+
+// Returns the row for a constituent, or nil if it isn't in the table
+func (t *AstronomicalTable) Row(name string) *AstronomicalTableRow {
+	for _, r := range t.Rows {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
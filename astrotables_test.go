@@ -0,0 +1,61 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/ryan-lang/tides/astronomy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAstronomicalTable(t *testing.T) {
+	table, err := tides.NewAstronomicalTable(2023, 2025, tides.NODE_FACTORS_SCHUREMAN, astronomy.Settings{})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2023, 2024, 2025}, table.Years)
+	assert.Equal(t, len(tides.CONSTITUENT_NAMES), len(table.Rows))
+
+	// solar constituents are fixed by the hour angle of the mean sun at midnight
+	s2 := table.Row("S2")
+	s1 := table.Row("S1")
+	for i := range table.Years {
+		assert.InDelta(t, 0, math.Mod(s2.EquilibriumArguments[i]+180, 360)-180, 1e-6)
+		assert.InDelta(t, 180, s1.EquilibriumArguments[i], 1e-6)
+		assert.Equal(t, 1.0, s2.FormFactors[i])
+	}
+	assert.InDelta(t, 30.0, s2.Speed, 1e-9)
+
+	// K1's argument is 90 + h at the start of the year, plus u; f follows the lunar node (Pugh table 4.3)
+	k1 := table.Row("K1")
+	m2 := table.Row("M2")
+	for i, year := range table.Years {
+		h, _ := (&astronomy.Astro{Time: time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)}).SolarLongitude()
+		N, _ := (&astronomy.Astro{Time: time.Date(year, 7, 2, 12, 0, 0, 0, time.UTC)}).LunarNode()
+		N *= astronomy.DEG_TO_RAD
+
+		u := -8.9*math.Sin(N) + 0.7*math.Sin(2*N)
+		expected := math.Mod(90+h+u+360, 360)
+		assert.InDelta(t, expected, k1.EquilibriumArguments[i], 0.3)
+		assert.InDelta(t, 1.006+0.115*math.Cos(N)-0.009*math.Cos(2*N), k1.FormFactors[i], 0.005)
+		assert.InDelta(t, 1.000-0.037*math.Cos(N), m2.FormFactors[i], 0.002)
+	}
+
+	assert.Nil(t, table.Row("X9"))
+
+	_, err = tides.NewAstronomicalTable(2025, 2023, tides.NODE_FACTORS_SCHUREMAN, astronomy.Settings{})
+	assert.Error(t, err)
+}
+
+func TestAstronomicalTableForeman(t *testing.T) {
+	schureman, err := tides.NewAstronomicalTable(2024, 2024, tides.NODE_FACTORS_SCHUREMAN, astronomy.Settings{})
+	assert.NoError(t, err)
+	foreman, err := tides.NewAstronomicalTable(2024, 2024, tides.NODE_FACTORS_FOREMAN, astronomy.Settings{})
+	assert.NoError(t, err)
+
+	// the formulations agree closely for the major constituents
+	for _, name := range []string{"M2", "K1", "O1", "N2"} {
+		assert.InDelta(t, schureman.Row(name).FormFactors[0], foreman.Row(name).FormFactors[0], 0.02, name)
+		assert.InDelta(t, schureman.Row(name).EquilibriumArguments[0], foreman.Row(name).EquilibriumArguments[0], 1, name)
+	}
+}
@@ -0,0 +1,101 @@
+package astro
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/ryan-lang/tides/astronomy"
+	"github.com/spf13/cobra"
+)
+
+var startYear, endYear int
+var table, nodeFactors, astroTheory string
+var deltaT bool
+
+var AstroCmd = &cobra.Command{
+	Use:   "astro",
+	Short: "print equilibrium argument & node factor tables",
+	Long: `Print the equilibrium arguments (V0+u) at the start of each year, and the node factors (f) at the middle
+of each year, for every constituent in the catalog, laid out like NOAA's Tables 14 & 15.
+
+Example:
+tides astro --year 2024 --end-year 2028 --table 14
+	`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if endYear == 0 {
+			endYear = startYear
+		}
+
+		method, err := tides.ParseNodeFactorMethod(nodeFactors)
+		if err != nil {
+			log.Fatalf("Failed to parse node factors: %v", err)
+		}
+		theory, err := astronomy.ParseTheory(astroTheory)
+		if err != nil {
+			log.Fatalf("Failed to parse astronomy theory: %v", err)
+		}
+
+		t, err := tides.NewAstronomicalTable(startYear, endYear, method, astronomy.Settings{DeltaT: deltaT, Theory: theory})
+		if err != nil {
+			log.Fatalf("Failed to calculate tables: %v", err)
+		}
+
+		switch table {
+		case "14":
+			printTable14(t)
+		case "15":
+			printTable15(t)
+		case "all":
+			printTable14(t)
+			fmt.Println()
+			printTable15(t)
+		default:
+			log.Fatalf("Unknown table: %s (expected 14, 15 or all)", table)
+		}
+	},
+}
+
+func printTable14(t *tides.AstronomicalTable) {
+	fmt.Println("Table 14. Equilibrium argument (V0+u) for meridian of Greenwich at beginning of each year")
+	printHeader(t)
+	for _, row := range t.Rows {
+		fmt.Printf("%-6s %12.7f", row.Name, row.Speed)
+		for _, v := range row.EquilibriumArguments {
+			fmt.Printf(" %7.1f", v)
+		}
+		fmt.Println()
+	}
+}
+
+func printTable15(t *tides.AstronomicalTable) {
+	fmt.Println("Table 15. Node factor (f) for middle of each year")
+	printHeader(t)
+	for _, row := range t.Rows {
+		fmt.Printf("%-6s %12.7f", row.Name, row.Speed)
+		for _, v := range row.FormFactors {
+			fmt.Printf(" %7.3f", v)
+		}
+		fmt.Println()
+	}
+}
+
+func printHeader(t *tides.AstronomicalTable) {
+	fmt.Printf("%-6s %12s", "Name", "Speed")
+	for _, year := range t.Years {
+		fmt.Printf(" %7d", year)
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 19+8*len(t.Years)))
+}
+
+func init() {
+	AstroCmd.PersistentFlags().IntVarP(&startYear, "year", "y", time.Now().Year(), "first year of the table")
+	AstroCmd.PersistentFlags().IntVarP(&endYear, "end-year", "", 0, "last year of the table; defaults to --year")
+	AstroCmd.PersistentFlags().StringVarP(&table, "table", "t", "all", "table to print (14, 15, all)")
+	AstroCmd.PersistentFlags().StringVarP(&nodeFactors, "node-factors", "", "", "node factor formulation (schureman, foreman)")
+	AstroCmd.PersistentFlags().BoolVarP(&deltaT, "delta-t", "", false, "evaluate the astronomical arguments in terrestrial time (UT + delta-t)")
+	AstroCmd.PersistentFlags().StringVarP(&astroTheory, "astro-theory", "", string(astronomy.THEORY_TRUNCATED), "series for the mean lunar & solar elements (truncated, meeus)")
+}
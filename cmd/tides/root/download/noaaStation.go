@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/ryan-lang/noaa-tidesandcurrents/client/dataApi"
 	"github.com/ryan-lang/noaa-tidesandcurrents/client/metadataApi"
@@ -13,8 +15,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const NOAA_METADATA_API_URL = "https://api.tidesandcurrents.noaa.gov/mdapi/prod/webapi"
+
 var stationId string
 
+// NOAA reports the station timezone as a standard time abbreviation; these are the ones used by CO-OPS stations
+var noaaTimezones = map[string]string{
+	"AST":  "America/Puerto_Rico",
+	"EST":  "America/New_York",
+	"CST":  "America/Chicago",
+	"MST":  "America/Denver",
+	"PST":  "America/Los_Angeles",
+	"AKST": "America/Anchorage",
+	"HAST": "Pacific/Honolulu",
+	"HST":  "Pacific/Honolulu",
+	"SST":  "Pacific/Pago_Pago",
+	"CHST": "Pacific/Guam",
+	"GMT":  "UTC",
+}
+
+type noaaStation struct {
+	Name      string  `json:"name"`
+	State     string  `json:"state"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+	Timezone  string  `json:"timezone"`
+}
+
 var noaaStationCmd = &cobra.Command{
 	Use:   "noaaStation",
 	Short: "Download NOAA station data & save to local file",
@@ -25,6 +52,11 @@ tides download noaaStation --station-id 9447130
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		station, err := downloadNOAAStation(stationId)
+		if err != nil {
+			log.Printf("Error downloading NOAA station metadata: %s\n", err)
+		}
+
 		harmonicsRes, err := downloadNOAAHarmonics(stationId)
 		if err != nil {
 			log.Printf("Error downloading NOAA harmonic constituent data: %s\n", err)
@@ -45,9 +77,14 @@ tides download noaaStation --station-id 9447130
 		}
 
 		document := &tides.StationDocument{
+			Name:                 station.Name,
+			Latitude:             station.Latitude,
+			Longitude:            station.Longitude,
+			Timezone:             station.Timezone,
 			HarmonicConstituents: harmonicsRes,
 			Datums:               datumRes,
 			TidePredOffsets:      tidePredOffsets,
+			Units:                tides.UNITS_METERS,
 		}
 
 		json, err := json.Marshal(document)
@@ -148,3 +185,47 @@ func downloadNOAAOffsets(stationId string) (*tides.TidePredOffsets, error) {
 
 	return o, nil
 }
+
+func downloadNOAAStation(stationId string) (*tides.StationDocument, error) {
+
+	// the metadata client doesn't expose the station name or position, so request the station resource directly
+	res, err := http.Get(fmt.Sprintf("%s/stations/%s.json", NOAA_METADATA_API_URL, stationId))
+	if err != nil {
+		return &tides.StationDocument{}, fmt.Errorf("error getting station metadata: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return &tides.StationDocument{}, fmt.Errorf("error getting station metadata: %s", res.Status)
+	}
+
+	var body struct {
+		Stations []*noaaStation `json:"stations"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return &tides.StationDocument{}, fmt.Errorf("error parsing station metadata: %s", err)
+	}
+	if len(body.Stations) == 0 {
+		return &tides.StationDocument{}, fmt.Errorf("station not found: %s", stationId)
+	}
+
+	// transmute into our struct format
+	station := body.Stations[0]
+	name := station.Name
+	if station.State != "" {
+		name = fmt.Sprintf("%s, %s", station.Name, station.State)
+	}
+
+	timezone, ok := noaaTimezones[strings.ToUpper(station.Timezone)]
+	if !ok && station.Timezone != "" {
+		log.Printf("Unknown NOAA station timezone %q; set the station timezone manually\n", station.Timezone)
+	}
+
+	return &tides.StationDocument{
+		Name:      name,
+		Latitude:  station.Latitude,
+		Longitude: station.Longitude,
+		Timezone:  timezone,
+	}, nil
+}
@@ -0,0 +1,159 @@
+package lows
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/ryan-lang/tides"
+	"github.com/spf13/cobra"
+)
+
+var dataDir, stationId, units, datum, intervalStr, tz, timeMode string
+var dateFrom, dateTo, window, sortBy string
+var maxLevel float64
+var margin time.Duration
+
+var LowsCmd = &cobra.Command{
+	Use:   "lows",
+	Short: "find daylight low tides below a level",
+	Long: `Find the low tides at or below a level that fall between sunrise and sunset at the station
+(or within a custom local time window), ranked by how low they are and how long the exposure lasts.
+Sunrise and sunset require the station's latitude & longitude.
+
+Example:
+tides lows --station 9447130 --max-level -0.3 --from 2024-05-01 --to 2024-09-01
+tides lows --station 9447130 --max-level 0 --window 06:00-10:00 --sort time
+	`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		// load harmonics data
+		har, err := tides.LoadHarmonicsFromFile(dataDir, stationId)
+		if err != nil {
+			log.Fatalf("error loading station data: %s", err)
+		}
+
+		// resolve the local timezone
+		mode, err := tides.ParseTimeMode(timeMode)
+		if err != nil {
+			log.Fatalf("Failed to parse time mode: %v", err)
+		}
+		loc, err := tides.ResolveLocation(tz, har, mode, time.Now())
+		if err != nil {
+			log.Fatalf("Failed to resolve timezone: %v", err)
+		}
+
+		// the season defaults to the next 90 days
+		startDate, _ := tides.DayBounds(time.Now(), loc)
+		endDate := startDate.AddDate(0, 0, 90)
+		if dateFrom != "" {
+			startDate = dateParseFatal(dateFrom, loc)
+		}
+		if dateTo != "" {
+			endDate = dateParseFatal(dateTo, loc)
+		}
+
+		// dates are read in the zone as it is now, but results are reported in the standard offset of the start's year
+		loc, err = tides.ResolveLocation(tz, har, mode, startDate)
+		if err != nil {
+			log.Fatalf("Failed to resolve timezone: %v", err)
+		}
+
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			log.Fatalf("Failed to parse interval: %v", err)
+		}
+
+		lengthUnits, err := tides.ParseLengthUnit(units)
+		if err != nil {
+			log.Fatalf("Failed to parse units: %v", err)
+		}
+
+		sortOrder, err := tides.ParseLowsSort(sortBy)
+		if err != nil {
+			log.Fatalf("Failed to parse sort order: %v", err)
+		}
+
+		opts := []tides.DaylightLowsOpt{
+			tides.WithLowsSort(sortOrder),
+			tides.WithDaylightMargin(margin),
+		}
+		if window != "" {
+			from, to, err := parseWindow(window)
+			if err != nil {
+				log.Fatalf("Failed to parse window: %v", err)
+			}
+			opts = append(opts, tides.WithLocalWindow(from, to))
+		}
+
+		prediction := har.NewRangePrediction(startDate, endDate,
+			tides.WithDatum(datum),
+			tides.WithUnits(lengthUnits),
+			tides.WithInterval(interval),
+			tides.WithLocation(loc),
+		)
+
+		lows, err := prediction.PredictDaylightLows(maxLevel, opts...)
+		if err != nil {
+			log.Fatalf("Failed to find lows: %v", err)
+		}
+
+		// print results
+		for _, l := range lows {
+			fmt.Printf("%s\t%f%s\t%s-%s\t%s\twindow %s-%s\n",
+				l.Low.Time.Format(time.RFC3339),
+				l.Low.Level,
+				prediction.Units,
+				l.ExposureStart.Format("15:04"),
+				l.ExposureEnd.Format("15:04"),
+				l.Exposure.Round(time.Minute),
+				l.WindowStart.Format("15:04"),
+				l.WindowEnd.Format("15:04"),
+			)
+		}
+	},
+}
+
+func init() {
+	LowsCmd.PersistentFlags().StringVarP(&stationId, "station", "s", "", "station identifier (e.g. NOAA station ID); must match json file in data directory")
+	LowsCmd.PersistentFlags().StringVarP(&dataDir, "data-dir", "d", "./data", "data directory containing station data")
+	LowsCmd.PersistentFlags().StringVarP(&datum, "datum", "m", "mllw", "datum for levels, including --max-level")
+	LowsCmd.PersistentFlags().StringVarP(&units, "units", "u", "m", "units for levels, including --max-level (m, cm, mm, ft, in)")
+	LowsCmd.PersistentFlags().Float64VarP(&maxLevel, "max-level", "l", 0, "only lows at or below this level")
+	LowsCmd.PersistentFlags().StringVarP(&window, "window", "w", "", "local time window for lows (eg. 06:00-10:00); defaults to sunrise to sunset")
+	LowsCmd.PersistentFlags().DurationVarP(&margin, "margin", "", 0, "widen the sunrise-sunset window by this much at both ends (eg. 30m)")
+	LowsCmd.PersistentFlags().StringVarP(&sortBy, "sort", "", string(tides.LOWS_SORT_LEVEL), "ranking of lows (level, exposure, time)")
+	LowsCmd.PersistentFlags().StringVarP(&intervalStr, "interval", "i", "6m", "interval between predictions; sets the precision of lows & exposure times")
+	LowsCmd.PersistentFlags().StringVarP(&dateFrom, "from", "", "", "start date (eg. 2024-05-01); defaults to today")
+	LowsCmd.PersistentFlags().StringVarP(&dateTo, "to", "", "", "end date (eg. 2024-09-01); defaults to 90 days after the start")
+	LowsCmd.PersistentFlags().StringVarP(&tz, "tz", "", "station", "timezone for days & times: station, local, utc, or an IANA name (eg. Pacific/Honolulu)")
+	LowsCmd.PersistentFlags().StringVarP(&timeMode, "time-mode", "", "lst_ldt", "lst_ldt (observe daylight saving time) or lst (local standard time all year)")
+	LowsCmd.MarkPersistentFlagRequired("station")
+}
+
+// parses <hh:mm>-<hh:mm> into offsets from local midnight
+func parseWindow(s string) (time.Duration, time.Duration, error) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected <hh:mm>-<hh:mm>, got %s", s)
+	}
+	var offsets [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, err
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return offsets[0], offsets[1], nil
+}
+
+func dateParseFatal(s string, loc *time.Location) time.Time {
+	d, err := dateparse.ParseIn(s, loc)
+	if err != nil {
+		log.Fatalf("Failed to parse date: %v", err)
+	}
+	return d
+}
@@ -3,6 +3,10 @@ package predict
 import (
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/araddon/dateparse"
@@ -10,12 +14,21 @@ import (
 	"github.com/olebedev/when/rules/common"
 	"github.com/olebedev/when/rules/en"
 	"github.com/ryan-lang/tides"
+	"github.com/ryan-lang/tides/astronomy"
 	"github.com/spf13/cobra"
 )
 
 var dateUntil, dateSince, dateFrom, dateTo string
-var dataDir, stationId, units, datum, intervalStr string
-var printUnits, printTimes, extrema bool
+var dataDir, stationId, units, datum, intervalStr, tz, timeMode, day string
+var printUnits, printTimes, printDatumPath, extrema, currents bool
+var speedUnits, pressureFile string
+var scenarioFile, scenarioName, nodal, nodeFactors string
+var seasonal, deltaT, solunar, state, decompose bool
+var astroTheory string
+var referencePressure float64
+var minProminence, doubleRatio, standRate float64
+var minSeparation time.Duration
+var ellipsoidSeparations []string
 
 var PredictCmd = &cobra.Command{
 	Use:   "predict",
@@ -34,8 +47,27 @@ to quickly create a Cobra application.`,
 			log.Fatalf("error loading station data: %s", err)
 		}
 
+		// add any user-supplied ellipsoid separations to the datum graph
+		for _, sep := range ellipsoidSeparations {
+			datumName, value, err := parseEllipsoidSeparation(sep)
+			if err != nil {
+				log.Fatalf("Failed to parse ellipsoid separation: %v", err)
+			}
+			har.AddEllipsoidSeparation(datumName, value)
+		}
+
+		// resolve the output timezone
+		mode, err := tides.ParseTimeMode(timeMode)
+		if err != nil {
+			log.Fatalf("Failed to parse time mode: %v", err)
+		}
+		loc, err := tides.ResolveLocation(tz, har, mode, time.Now())
+		if err != nil {
+			log.Fatalf("Failed to resolve timezone: %v", err)
+		}
+
 		// setup dates
-		startDate := time.Now()
+		startDate := time.Now().In(loc)
 		endDate := startDate
 		interval := time.Minute
 
@@ -45,15 +77,24 @@ to quickly create a Cobra application.`,
 		w.Add(common.All...)
 
 		// parse the dates
+		if day != "" {
+			startDate, endDate = tides.DayBounds(dayParseFatal(w, day, loc), loc)
+		}
 		if dateSince != "" {
-			startDate = whenParseFatal(w, dateSince)
+			startDate = whenParseFatal(w, dateSince, loc)
 		} else if dateFrom != "" {
-			startDate = dateParseFatal(dateFrom)
+			startDate = dateParseFatal(dateFrom, loc)
 		}
 		if dateUntil != "" {
-			endDate = whenParseFatal(w, dateUntil)
+			endDate = whenParseFatal(w, dateUntil, loc)
 		} else if dateTo != "" {
-			endDate = dateParseFatal(dateTo)
+			endDate = dateParseFatal(dateTo, loc)
+		}
+
+		// dates are read in the zone as it is now, but results are reported in the standard offset of the start's year
+		loc, err = tides.ResolveLocation(tz, har, mode, startDate)
+		if err != nil {
+			log.Fatalf("Failed to resolve timezone: %v", err)
 		}
 
 		// parse the interval
@@ -64,19 +105,174 @@ to quickly create a Cobra application.`,
 			}
 		}
 
+		// parse the units
+		lengthUnits, err := tides.ParseLengthUnit(units)
+		if err != nil {
+			log.Fatalf("Failed to parse units: %v", err)
+		}
+
+		parsedSpeedUnits, err := tides.ParseSpeedUnit(speedUnits)
+		if err != nil {
+			log.Fatalf("Failed to parse speed units: %v", err)
+		}
+
+		nodalCorrection, err := tides.ParseNodalCorrection(nodal)
+		if err != nil {
+			log.Fatalf("Failed to parse nodal correction: %v", err)
+		}
+
+		// optionally, override the station's node factor formulation
+		if nodeFactors != "" {
+			har.NodeFactors, err = tides.ParseNodeFactorMethod(nodeFactors)
+			if err != nil {
+				log.Fatalf("Failed to parse node factors: %v", err)
+			}
+		}
+
+		theory, err := astronomy.ParseTheory(astroTheory)
+		if err != nil {
+			log.Fatalf("Failed to parse astronomy theory: %v", err)
+		}
+
 		// extrema requires a range
-		if extrema && endDate.Equal(startDate) {
+		if (extrema || solunar) && endDate.Equal(startDate) {
 			endDate = startDate.Add(time.Hour * 24)
 		}
 
-		// create a prediction
-		prediction := har.NewRangePrediction(startDate, endDate,
+		opts := []tides.PredictionOpt{
 			tides.WithDatum(datum),
-			tides.WithUnits(units),
+			tides.WithUnits(lengthUnits),
+			tides.WithSpeedUnits(parsedSpeedUnits),
 			tides.WithInterval(interval),
-		)
+			tides.WithLocation(loc),
+			tides.WithNodalCorrection(nodalCorrection),
+			tides.WithAstronomyTheory(theory),
+		}
+		if deltaT {
+			opts = append(opts, tides.WithDeltaT())
+		}
+
+		// optionally, adjust for atmospheric pressure
+		if pressureFile != "" {
+			pressure, err := tides.LoadPressureFromFile(pressureFile)
+			if err != nil {
+				log.Fatalf("Failed to load pressure file: %v", err)
+			}
+			opts = append(opts, tides.WithAtmosphericAdjustment(&tides.AtmosphericAdjustment{
+				Pressure:          pressure,
+				ReferencePressure: referencePressure,
+			}))
+		}
+
+		// optionally, add the seasonal mean sea level anomaly
+		if seasonal {
+			if har.SeasonalMSL == nil {
+				log.Fatalf("Station has no seasonal msl table")
+			}
+			opts = append(opts, tides.WithSeasonalCorrection())
+		}
+
+		// optionally, project sea level rise along a scenario curve
+		if scenarioFile != "" {
+			scenario, err := tides.LoadSeaLevelScenarioFromFile(scenarioFile, scenarioName)
+			if err != nil {
+				log.Fatalf("Failed to load sea level scenario: %v", err)
+			}
+			trend := &tides.SeaLevelTrend{Scenario: scenario}
+			if har.SeaLevelTrend != nil {
+				trend.EpochMidpoint = har.SeaLevelTrend.EpochMidpoint
+			}
+			opts = append(opts, tides.WithSeaLevelTrend(trend))
+		}
+
+		// optionally, tune which turning points are reported as highs & lows
+		if minProminence > 0 || minSeparation > 0 || doubleRatio > 0 || standRate > 0 {
+			opts = append(opts, tides.WithExtremaConfig(&tides.ExtremaConfig{
+				MinProminence: minProminence,
+				MinSeparation: minSeparation,
+				DoubleRatio:   doubleRatio,
+				StandRate:     standRate,
+			}))
+		}
+
+		// create a prediction
+		prediction := har.NewRangePrediction(startDate, endDate, opts...)
+		if err := prediction.Validate(); err != nil {
+			log.Fatalf("Invalid prediction: %v", err)
+		}
+
+		if printDatumPath {
+			conv, err := prediction.DatumConversion()
+			if err != nil {
+				log.Fatalf("Failed to convert datum: %v", err)
+			}
+			if conv != nil {
+				fmt.Fprintf(os.Stderr, "datum conversion: %s\n", conv)
+			}
+		}
+
+		if solunar {
+
+			// get prediction
+			summaries, err := prediction.PredictDailySummaries()
+			if err != nil {
+				log.Fatalf("Failed to summarize days: %v", err)
+			}
+			if !har.HasLocation() {
+				fmt.Fprintln(os.Stderr, "station has no latitude & longitude; sun, moon & solunar times are omitted")
+			}
+
+			// print results
+			for _, summary := range summaries {
+				printDailySummary(summary, prediction.Units, printUnits)
+			}
+		} else if state {
+
+			// get the state at the start time
+			result, err := prediction.StateAt(startDate)
+			if err != nil {
+				log.Fatalf("Failed to calculate tide state: %v", err)
+			}
 
-		if extrema {
+			// print results
+			direction := "falling"
+			if result.Rising {
+				direction = "rising"
+			}
+			fmt.Printf("%s\t%f%s\t%s %f%s/h\n", result.Time.Format(time.RFC3339), result.Level, prediction.Units, direction, result.Rate, prediction.Units)
+			for _, ex := range []*tides.PredictionValue{result.Previous, result.Next} {
+				fmt.Printf("%s\t%s\t%f%s\n", ex.Time.Format(time.RFC3339), ex.Type, ex.Level, prediction.Units)
+			}
+			fmt.Printf("cycle\t%.0f%%\n", result.Cycle*100)
+		} else if currents {
+
+			// get prediction
+			var results []*tides.CurrentValue
+			if extrema {
+				results, err = prediction.PredictCurrentEvents()
+			} else {
+				results, err = prediction.PredictCurrents()
+			}
+			if err != nil {
+				log.Fatalf("Failed to predict currents: %v", err)
+			}
+
+			// print results
+			for _, result := range results {
+				if printTimes {
+					fmt.Printf("%s\t", result.Time.Format(time.RFC3339))
+				}
+				if extrema {
+					fmt.Printf("%s\t", result.Type)
+				}
+				fmt.Printf("%f", result.Speed)
+				if printUnits {
+					fmt.Printf("%s", prediction.SpeedUnits)
+				}
+				fmt.Printf("\t%.0f", result.Direction)
+				fmt.Println()
+			}
+		} else if extrema {
 
 			// get prediction
 			results := prediction.PredictExtrema()
@@ -86,11 +282,58 @@ to quickly create a Cobra application.`,
 				if printTimes {
 					fmt.Printf("%s\t", result.Time.Format(time.RFC3339))
 				}
-				fmt.Printf("%s\t", result.Type)
+				fmt.Printf("%s\t", result.Class)
 				fmt.Printf("%f", result.Level)
 				if printUnits {
 					fmt.Printf("%s", prediction.Units)
 				}
+				if result.Double {
+					fmt.Printf("\tdouble")
+				}
+				if result.Stand != nil {
+					fmt.Printf("\tstand %s-%s", result.Stand.Start.Format("15:04"), result.Stand.End.Format("15:04"))
+				}
+				fmt.Println()
+			}
+		} else if decompose {
+
+			// get prediction
+			results, err := prediction.PredictDecomposition()
+			if err != nil {
+				log.Fatalf("Failed to decompose prediction: %v", err)
+			}
+
+			// one column for each constituent the station uses, then each species
+			names := make([]string, 0)
+			for _, c := range har.Constituents {
+				if c.Amplitude != 0 {
+					names = append(names, c.Name)
+				}
+			}
+			if printTimes {
+				fmt.Print("time\t")
+			}
+			fmt.Print("total")
+			for _, name := range names {
+				fmt.Printf("\t%s", name)
+			}
+			for _, species := range tides.CONSTITUENT_SPECIES {
+				fmt.Printf("\t%s", species)
+			}
+			fmt.Println()
+
+			// print results
+			for _, result := range results {
+				if printTimes {
+					fmt.Printf("%s\t", result.Time.Format(time.RFC3339))
+				}
+				fmt.Printf("%f", result.Level)
+				for _, name := range names {
+					fmt.Printf("\t%f", result.Constituents[name])
+				}
+				for _, species := range tides.CONSTITUENT_SPECIES {
+					fmt.Printf("\t%f", result.Species[species])
+				}
 				fmt.Println()
 			}
 		} else {
@@ -118,31 +361,123 @@ to quickly create a Cobra application.`,
 func init() {
 	PredictCmd.PersistentFlags().StringVarP(&stationId, "station", "s", "", "station identifier (e.g. NOAA station ID); must match json file in data directory")
 	PredictCmd.PersistentFlags().StringVarP(&dataDir, "data-dir", "d", "./data", "data directory containing station data")
-	PredictCmd.PersistentFlags().StringVarP(&datum, "datum", "m", "mllw", "datum to use for prediction (mllw, mhhw, mhw, msl, mslw, msw, naw, stnd)")
-	PredictCmd.PersistentFlags().StringVarP(&units, "units", "u", "m", "m (metric) or ft (imperial)")
+	PredictCmd.PersistentFlags().StringVarP(&datum, "datum", "m", "mllw", "datum to use for prediction (mllw, mhhw, mhw, msl, stnd, or any geodetic/local datum linked in the station data, e.g. navd88)")
+	PredictCmd.PersistentFlags().StringSliceVarP(&ellipsoidSeparations, "ellipsoid-separation", "", nil, "separation between a datum and the ellipsoid, in meters (e.g. NAVD88=-23.4); enables --datum ellipsoid")
+	PredictCmd.PersistentFlags().BoolVarP(&printDatumPath, "print-datum-path", "", false, "print the datum conversion path used to stderr")
+	PredictCmd.PersistentFlags().StringVarP(&units, "units", "u", "m", "units for prediction output (m, cm, mm, ft, in)")
 	PredictCmd.PersistentFlags().StringVarP(&intervalStr, "interval", "i", "1m", "interval between predictions (e.g. 1h, 30m, 15m)")
 	PredictCmd.PersistentFlags().BoolVarP(&printUnits, "print-units", "", false, "print units in output")
 	PredictCmd.PersistentFlags().BoolVarP(&printTimes, "print-times", "", false, "print times in output")
-	PredictCmd.PersistentFlags().BoolVarP(&extrema, "extrema", "e", false, "returns tide extrema (highs and lows) only")
+	PredictCmd.PersistentFlags().BoolVarP(&extrema, "extrema", "e", false, "returns tide extrema only, classified as higher/lower highs and lows (HH, LH, HL, LL); with --currents, returns max flood, max ebb and slack water")
+	PredictCmd.PersistentFlags().Float64VarP(&minProminence, "min-prominence", "", 0, "with --extrema, drop highs & lows that differ in level by less than this, in the output units")
+	PredictCmd.PersistentFlags().DurationVarP(&minSeparation, "min-separation", "", 0, "with --extrema, drop highs & lows closer together than this (e.g. 2h)")
+	PredictCmd.PersistentFlags().Float64VarP(&doubleRatio, "double-ratio", "", 0, "with --extrema, report double high & low waters whose dip is less than this fraction of the tidal range (e.g. 0.2)")
+	PredictCmd.PersistentFlags().Float64VarP(&standRate, "stand-rate", "", 0, "with --extrema, report the stand around each high & low, where the level changes less than this per hour, in the output units")
+	PredictCmd.PersistentFlags().BoolVarP(&solunar, "solunar", "", false, "print a daily summary of highs & lows with sun & moon times and solunar periods; station must have a latitude & longitude")
+	PredictCmd.PersistentFlags().BoolVarP(&state, "state", "", false, "print the tide state at the start time: level, rising or falling & rate, previous & next high or low, and position in the tidal cycle")
+	PredictCmd.PersistentFlags().BoolVarP(&decompose, "decompose", "", false, "print the contribution of each constituent & species (long-period, diurnal, semidiurnal, shallow-water) alongside the total, with a header row; reference stations only")
+	PredictCmd.PersistentFlags().BoolVarP(&currents, "currents", "c", false, "predict tidal currents (signed speed and direction) instead of heights; station must have current harmonics")
+	PredictCmd.PersistentFlags().StringVarP(&speedUnits, "speed-units", "", "kn", "units for current predictions (m/s, cm/s, ft/s, kn)")
+	PredictCmd.PersistentFlags().StringVarP(&pressureFile, "pressure-file", "", "", "csv file of <time>,<pressure hPa> used to apply the inverse barometer correction")
+	PredictCmd.PersistentFlags().Float64VarP(&referencePressure, "reference-pressure", "", tides.DEFAULT_REFERENCE_PRESSURE, "reference pressure for the inverse barometer correction, in hPa")
+	PredictCmd.PersistentFlags().StringVarP(&nodal, "nodal", "", "continuous", "how often node factors are evaluated (continuous, daily, yearly, none)")
+	PredictCmd.PersistentFlags().StringVarP(&nodeFactors, "node-factors", "", "", "node factor formulation (schureman, foreman); defaults to the station's")
+	PredictCmd.PersistentFlags().BoolVarP(&deltaT, "delta-t", "", false, "evaluate the astronomical arguments in terrestrial time (UT + delta-t)")
+	PredictCmd.PersistentFlags().StringVarP(&astroTheory, "astro-theory", "", string(astronomy.THEORY_TRUNCATED), "series for the mean lunar & solar elements (truncated, meeus)")
+	PredictCmd.PersistentFlags().BoolVarP(&seasonal, "seasonal", "", false, "add the station's seasonal mean sea level anomaly")
+	PredictCmd.PersistentFlags().StringVarP(&scenarioFile, "slr-scenario-file", "", "", "csv file of sea level rise scenarios, with a header of year,<scenario names...> and offsets in mm")
+	PredictCmd.PersistentFlags().StringVarP(&scenarioName, "slr-scenario", "", "Intermediate", "name of the sea level rise scenario column to use")
 	PredictCmd.PersistentFlags().StringVarP(&dateSince, "since", "", "", "relative start date for prediction (eg. yesterday, last friday)")
 	PredictCmd.PersistentFlags().StringVarP(&dateUntil, "until", "", "", "relative end date for prediction (eg. tomorrow, next friday)")
 	PredictCmd.PersistentFlags().StringVarP(&dateFrom, "from", "", "", "absolute start date for prediction (eg. 2019-01-01T00:00:00Z)")
 	PredictCmd.PersistentFlags().StringVarP(&dateTo, "to", "", "", "absolute end date for prediction (eg. 2019-01-01T00:00:00Z)")
+	PredictCmd.PersistentFlags().StringVarP(&day, "day", "", "", "calendar day to predict, in the output timezone (eg. today, tomorrow, 2019-01-01)")
+	PredictCmd.PersistentFlags().StringVarP(&tz, "tz", "", "local", "timezone for input & output times: station, local, utc, or an IANA name (eg. Pacific/Honolulu)")
+	PredictCmd.PersistentFlags().StringVarP(&timeMode, "time-mode", "", "lst_ldt", "lst_ldt (observe daylight saving time) or lst (local standard time all year)")
 	PredictCmd.MarkPersistentFlagRequired("station")
 }
 
-func whenParseFatal(w *when.Parser, s string) time.Time {
-	parsed, err := w.Parse(s, time.Now())
+// prints the tides, sun & moon for a day, with each event on its own line in time order
+func printDailySummary(summary *tides.DailySummary, units tides.LengthUnit, printUnits bool) {
+	type line struct {
+		t    time.Time
+		text string
+	}
+	loc := summary.Start.Location()
+	lines := make([]line, 0)
+	add := func(t *time.Time, text string) {
+		if t != nil {
+			lines = append(lines, line{*t, fmt.Sprintf("%s\t%s", t.In(loc).Format("15:04"), text)})
+		}
+	}
+
+	if day := summary.SunAndMoon; day != nil {
+		add(day.Sunrise, "sunrise")
+		add(day.Sunset, "sunset")
+		add(day.Moonrise, "moonrise")
+		add(day.Moonset, "moonset")
+		add(day.MoonUpperTransit, "moon overhead")
+		add(day.MoonLowerTransit, "moon underfoot")
+	}
+	for _, period := range summary.Solunar {
+		lines = append(lines, line{period.Start, fmt.Sprintf("%s-%s\t%s solunar period", period.Start.In(loc).Format("15:04"), period.End.In(loc).Format("15:04"), period.Type)})
+	}
+	for _, ex := range summary.Extrema {
+		text := fmt.Sprintf("%s\t%s %f", ex.Time.In(loc).Format("15:04"), ex.Class, ex.Level)
+		if printUnits {
+			text += string(units)
+		}
+		lines = append(lines, line{ex.Time, text})
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].t.Before(lines[j].t) })
+
+	fmt.Print(summary.Start.Format("2006-01-02"))
+	if summary.SunAndMoon != nil {
+		fmt.Printf("\tmoon %.0f%% illuminated", summary.SunAndMoon.MoonIllumination*100)
+	}
+	fmt.Println()
+	for _, l := range lines {
+		fmt.Printf("\t%s\n", l.text)
+	}
+}
+
+func whenParseFatal(w *when.Parser, s string, loc *time.Location) time.Time {
+	parsed, err := w.Parse(s, time.Now().In(loc))
 	if err != nil {
 		log.Fatalf("Failed to parse date: %v", err)
 	} else if parsed == nil {
-		log.Fatalf("Failed to parse date: %s", dateUntil)
+		log.Fatalf("Failed to parse date: %s", s)
 	}
 	return parsed.Time
 }
 
-func dateParseFatal(s string) time.Time {
-	d, err := dateparse.ParseLocal(s)
+// parses an absolute date, or falls back to a relative one (eg. today)
+func dayParseFatal(w *when.Parser, s string, loc *time.Location) time.Time {
+	if strings.EqualFold(s, "today") {
+		return time.Now().In(loc)
+	}
+	d, err := dateparse.ParseIn(s, loc)
+	if err == nil {
+		return d
+	}
+	return whenParseFatal(w, s, loc)
+}
+
+// parses <datum>=<separation in meters>
+func parseEllipsoidSeparation(s string) (string, float64, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, fmt.Errorf("expected <datum>=<meters>, got %s", s)
+	}
+	value, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return parts[0], value, nil
+}
+
+func dateParseFatal(s string, loc *time.Location) time.Time {
+	d, err := dateparse.ParseIn(s, loc)
 	if err != nil {
 		log.Fatalf("Failed to parse date: %v", err)
 	}
@@ -3,7 +3,9 @@ package root
 import (
 	"os"
 
+	"github.com/ryan-lang/tides/cmd/tides/root/astro"
 	"github.com/ryan-lang/tides/cmd/tides/root/download"
+	"github.com/ryan-lang/tides/cmd/tides/root/lows"
 	"github.com/ryan-lang/tides/cmd/tides/root/predict"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +24,8 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.AddCommand(astro.AstroCmd)
 	rootCmd.AddCommand(download.DownloadCmd)
+	rootCmd.AddCommand(lows.LowsCmd)
 	rootCmd.AddCommand(predict.PredictCmd)
 }
@@ -0,0 +1,115 @@
+package tides_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+// run with -race to check that a shared prediction is never written to
+
+func TestPredictRepeatable(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*6))
+
+	// each call returns the same, fresh results
+	first := prediction.Predict()
+	second := prediction.Predict()
+	assert.Equal(t, len(first), len(second))
+	assert.Equal(t, levels(first), levels(second))
+	if assert.NotEmpty(t, first) {
+		assert.NotSame(t, first[0], second[0])
+	}
+
+	extrema := prediction.PredictExtrema()
+	highs := prediction.PredictHighs()
+	assert.Equal(t, levels(extrema), levels(prediction.PredictExtrema()))
+	assert.Len(t, highs, len(extrema)/2)
+	assert.Equal(t, levels(first), levels(prediction.Predict()))
+}
+
+func TestPredictConcurrent(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24),
+		tides.WithInterval(time.Minute*6),
+		tides.WithDatum("MLLW"),
+		tides.WithExtremaConfig(&tides.ExtremaConfig{MinProminence: 0.05, StandRate: 0.05}),
+	)
+	testConcurrent(t, prediction, start)
+}
+
+func TestPredictConcurrentSubordinate(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*6))
+	testConcurrent(t, prediction, start)
+
+	// the offsets are applied to every point, not just the extrema, so the levels are continuous
+	results := prediction.Predict()
+	for i := 1; i < len(results); i++ {
+		assert.True(t, results[i].Time.After(results[i-1].Time), results[i].Time)
+		assert.Less(t, results[i].Time.Sub(results[i-1].Time), time.Minute*10, results[i].Time)
+		assert.InDelta(t, results[i-1].Level, results[i].Level, 0.1, results[i].Time)
+	}
+}
+
+// calls the prediction's methods from several goroutines at once, & checks they match the results of serial calls
+func testConcurrent(t *testing.T, prediction *tides.Prediction, start time.Time) {
+	times := []time.Time{start.Add(time.Minute * 7), start.Add(time.Hour * 5), start.Add(time.Hour*11 + time.Second*30)}
+
+	expectedLevels := levels(prediction.Predict())
+	expectedExtrema := levels(prediction.PredictExtrema())
+	expectedAt, err := prediction.PredictAt(times)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedState, err := prediction.StateAt(times[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			assert.Equal(t, expectedLevels, levels(prediction.Predict()))
+			assert.Equal(t, expectedExtrema, levels(prediction.PredictExtrema()))
+
+			at, err := prediction.PredictAt(times)
+			if assert.NoError(t, err) {
+				assert.Equal(t, levels(expectedAt), levels(at))
+			}
+
+			state, err := prediction.StateAt(times[1])
+			if assert.NoError(t, err) {
+				assert.Equal(t, expectedState.Level, state.Level)
+				assert.Equal(t, expectedState.Next.Time, state.Next.Time)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func levels(values []*tides.PredictionValue) []float64 {
+	result := make([]float64, len(values))
+	for i, v := range values {
+		result[i] = v.Level
+	}
+	return result
+}
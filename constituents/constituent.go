@@ -110,6 +110,26 @@ func (c *Constituent) FormFactor(a *astro.Astro) float64 {
 	return c.FormFactorFunc(a)
 }
 
+// u, using Foreman's satellite method; falls back to Schureman for constituents without satellite data
+func (c *Constituent) ForemanNodeFactor(a *astro.Astro) float64 {
+	satellites, ok := foremanSatellites[c.Name]
+	if !ok {
+		return c.NodeFactor(a)
+	}
+	_, u := foremanFactors(a, satellites)
+	return u
+}
+
+// f, using Foreman's satellite method; falls back to Schureman for constituents without satellite data
+func (c *Constituent) ForemanFormFactor(a *astro.Astro) float64 {
+	satellites, ok := foremanSatellites[c.Name]
+	if !ok {
+		return c.FormFactor(a)
+	}
+	f, _ := foremanFactors(a, satellites)
+	return f
+}
+
 func (c *CompoundConstituent) GetName() string {
 	return c.Name
 }
@@ -152,6 +172,24 @@ func (c *CompoundConstituent) FormFactor(a *astro.Astro) float64 {
 	return product
 }
 
+// u, using Foreman's satellite method for each member
+func (c *CompoundConstituent) ForemanNodeFactor(a *astro.Astro) float64 {
+	nodeFactor := 0.0
+	for _, member := range c.Members {
+		nodeFactor += member.Constituent.ForemanNodeFactor(a) * member.Factor
+	}
+	return nodeFactor
+}
+
+// f, using Foreman's satellite method for each member
+func (c *CompoundConstituent) ForemanFormFactor(a *astro.Astro) float64 {
+	product := 1.0
+	for _, member := range c.Members {
+		product *= math.Pow(member.Constituent.ForemanFormFactor(a), math.Abs(member.Factor))
+	}
+	return product
+}
+
 func DoodsonNumbers(a *astro.Astro) ([]float64, []float64) {
 	thsA, thsS := a.EquilibriumArgument()
 	sA, sS := a.LunarLongitude()
@@ -0,0 +1,99 @@
+package constituents
+
+import (
+	"math"
+
+	astro "github.com/ryan-lang/tides/astronomy"
+)
+
+type (
+	// A satellite of a main constituent, as in Foreman's tables. The argument of the satellite, relative to the
+	// main line, is dp*p + dN*N' + dpp*p' + phase, where N' = -N and all are in cycles.
+	satellite struct {
+		dp    float64
+		dN    float64
+		dpp   float64
+		phase float64
+		ratio float64 // amplitude relative to the main constituent
+	}
+)
+
+// Satellites of the main constituents, after Foreman (1977), "Manual for tidal heights analysis and prediction".
+// This is a reduction of his tables to the nodal satellites: those whose arguments also involve the lunar or solar
+// perigee (p, p') are omitted, so every entry has dp = dpp = 0 and f & u follow the 18.6 year node alone. They are
+// therefore close to, but not the same as, the IOS package's & UTide's. Constituents not listed here (e.g. M1 & L2,
+// whose satellites depend on latitude) use the Schureman formulas.
+var foremanSatellites = map[string][]satellite{
+	"MM": {
+		{0, -1, 0, 0.50, 0.0657},
+		{0, 1, 0, 0.50, 0.0649},
+	},
+	"MF": {
+		{0, 1, 0, 0.00, 0.4143},
+		{0, 2, 0, 0.00, 0.0387},
+	},
+	"Q1": {
+		{0, -1, 0, 0.00, 0.1886},
+		{0, -2, 0, 0.50, 0.0147},
+	},
+	"O1": {
+		{0, -1, 0, 0.00, 0.1886},
+		{0, -2, 0, 0.50, 0.0147},
+	},
+	"K1": {
+		{0, -1, 0, 0.50, 0.0200},
+		{0, 1, 0, 0.00, 0.1356},
+		{0, 2, 0, 0.50, 0.0029},
+	},
+	"J1": {
+		{0, -1, 0, 0.50, 0.0227},
+		{0, 1, 0, 0.00, 0.1690},
+	},
+	"OO1": {
+		{0, 1, 0, 0.00, 0.6398},
+		{0, 2, 0, 0.00, 0.1342},
+		{0, 3, 0, 0.00, 0.0086},
+	},
+	"2N2": {
+		{0, -1, 0, 0.50, 0.0373},
+	},
+	"N2": {
+		{0, -1, 0, 0.50, 0.0373},
+	},
+	"NU2": {
+		{0, -1, 0, 0.50, 0.0373},
+	},
+	"M2": {
+		{0, -1, 0, 0.50, 0.0373},
+	},
+	"LAM2": {
+		{0, -1, 0, 0.50, 0.0373},
+	},
+	"K2": {
+		{0, 1, 0, 0.00, 0.2980},
+		{0, 2, 0, 0.00, 0.0324},
+	},
+	"M3": {
+		{0, -1, 0, 0.50, 0.0564},
+	},
+}
+
+// Calculates f and u (in degrees) by summing the satellites of the constituent:
+// f * exp(iu) = 1 + sum(ratio * exp(2*pi*i*(dp*p + dN*N' + dpp*p' + phase)))
+func foremanFactors(a *astro.Astro, satellites []satellite) (f, u float64) {
+	p, _ := a.LunarPerigee()
+	N, _ := a.LunarNode()
+	pp, _ := a.SolarPerigee()
+	return satelliteSum(satellites, p/360, -N/360, pp/360)
+}
+
+// sums the satellites, given p, N' and p' in cycles
+func satelliteSum(satellites []satellite, p, Nprime, pp float64) (f, u float64) {
+	re, im := 1.0, 0.0
+	for _, s := range satellites {
+		arg := 2 * math.Pi * (s.dp*p + s.dN*Nprime + s.dpp*pp + s.phase)
+		re += s.ratio * math.Cos(arg)
+		im += s.ratio * math.Sin(arg)
+	}
+	return math.Hypot(re, im), RAD_TO_DEG * math.Atan2(im, re)
+}
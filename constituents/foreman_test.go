@@ -0,0 +1,117 @@
+package constituents
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	astro "github.com/ryan-lang/tides/astronomy"
+)
+
+// Node factor formulas from Pugh (1987), "Tides, Surges and Mean Sea-Level", table 4.3, in terms of the longitude
+// of the lunar node N
+var PUGH_NODE_FACTORS = []struct {
+	constituent Constituent
+	f           func(N float64) float64
+	u           func(N float64) float64
+}{
+	{
+		CONSTITUENT_MM,
+		func(N float64) float64 { return 1.000 - 0.130*math.Cos(N) },
+		func(N float64) float64 { return 0 },
+	},
+	{
+		CONSTITUENT_MF,
+		func(N float64) float64 { return 1.043 + 0.414*math.Cos(N) },
+		func(N float64) float64 { return -23.7*math.Sin(N) + 2.7*math.Sin(2*N) - 0.4*math.Sin(3*N) },
+	},
+	{
+		CONSTITUENT_O1,
+		func(N float64) float64 { return 1.009 + 0.187*math.Cos(N) - 0.015*math.Cos(2*N) },
+		func(N float64) float64 { return 10.8*math.Sin(N) - 1.3*math.Sin(2*N) + 0.2*math.Sin(3*N) },
+	},
+	{
+		CONSTITUENT_K1,
+		func(N float64) float64 { return 1.006 + 0.115*math.Cos(N) - 0.009*math.Cos(2*N) },
+		func(N float64) float64 { return -8.9*math.Sin(N) + 0.7*math.Sin(2*N) },
+	},
+	{
+		CONSTITUENT_M2,
+		func(N float64) float64 { return 1.000 - 0.037*math.Cos(N) },
+		func(N float64) float64 { return -2.1 * math.Sin(N) },
+	},
+	{
+		CONSTITUENT_K2,
+		func(N float64) float64 { return 1.024 + 0.286*math.Cos(N) + 0.008*math.Cos(2*N) },
+		func(N float64) float64 { return -17.7*math.Sin(N) + 0.7*math.Sin(2*N) },
+	},
+}
+
+func TestNodeFactorsAgainstTable(t *testing.T) {
+	for year := 2020; year < 2039; year++ {
+		a := &astro.Astro{Time: time.Date(year, 7, 2, 0, 0, 0, 0, time.UTC)}
+		N, _ := a.LunarNode()
+		N *= DEG_TO_RAD
+
+		for _, c := range PUGH_NODE_FACTORS {
+			expectedF, expectedU := c.f(N), c.u(N)
+
+			methods := map[string][2]float64{
+				"schureman": {c.constituent.FormFactor(a), c.constituent.NodeFactor(a)},
+				"foreman":   {c.constituent.ForemanFormFactor(a), c.constituent.ForemanNodeFactor(a)},
+			}
+			for method, fu := range methods {
+				u := math.Remainder(fu[1], 360)
+				if math.Abs(fu[0]-expectedF) > 0.015 {
+					t.Errorf("%s %s f (%d) failed, expected %f but got %f", method, c.constituent.Name, year, expectedF, fu[0])
+				}
+				if math.Abs(u-expectedU) > 1.0 {
+					t.Errorf("%s %s u (%d) failed, expected %f but got %f", method, c.constituent.Name, year, expectedU, u)
+				}
+			}
+		}
+	}
+}
+
+func TestForemanFallback(t *testing.T) {
+	a := &astro.Astro{Time: TEST_DATE}
+
+	// no satellite data, so the Schureman formulas are used
+	m1 := CONSTITUENT_M1
+	if m1.ForemanFormFactor(a) != m1.FormFactor(a) || m1.ForemanNodeFactor(a) != m1.NodeFactor(a) {
+		t.Errorf("ForemanFormFactor() failed, expected M1 to fall back to Schureman")
+	}
+	for _, name := range []string{"M1", "L2"} {
+		if _, ok := foremanSatellites[name]; ok {
+			t.Errorf("foremanSatellites failed, expected no satellites for %s", name)
+		}
+	}
+
+	// solar constituents have no nodal modulation
+	s2 := CONSTITUENT_S2
+	if s2.ForemanFormFactor(a) != 1 || s2.ForemanNodeFactor(a) != 0 {
+		t.Errorf("ForemanFormFactor() failed, expected S2 to be unity")
+	}
+
+	// compound constituents combine their members
+	m4 := CONSTITUENT_M4
+	m2 := CONSTITUENT_M2
+	if math.Abs(m4.ForemanFormFactor(a)-math.Pow(m2.ForemanFormFactor(a), 2)) > VAL_TOLERANCE {
+		t.Errorf("ForemanFormFactor() failed, expected M4 f to be M2 f squared")
+	}
+	if math.Abs(m4.ForemanNodeFactor(a)-2*m2.ForemanNodeFactor(a)) > VAL_TOLERANCE {
+		t.Errorf("ForemanNodeFactor() failed, expected M4 u to be twice M2 u")
+	}
+}
+
+func TestSatelliteSum(t *testing.T) {
+	// at the node extremes, the M2 satellite adds directly to or subtracts from the main line
+	f, u := satelliteSum(foremanSatellites["M2"], 0, 0, 0)
+	if math.Abs(f-0.9627) > VAL_TOLERANCE || math.Abs(u) > VAL_TOLERANCE {
+		t.Errorf("satelliteSum() failed, expected 0.9627, 0 but got %f, %f", f, u)
+	}
+	f, _ = satelliteSum(foremanSatellites["M2"], 0, 0.5, 0)
+	if math.Abs(f-1.0373) > VAL_TOLERANCE {
+		t.Errorf("satelliteSum() failed, expected 1.0373 but got %f", f)
+	}
+}
@@ -0,0 +1,389 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+const (
+	CURRENT_MAX_FLOOD          = "F"  // maximum flood current
+	CURRENT_MAX_EBB            = "E"  // maximum ebb current
+	CURRENT_SLACK_BEFORE_FLOOD = "SF" // slack water, as ebb turns to flood
+	CURRENT_SLACK_BEFORE_EBB   = "SE" // slack water, as flood turns to ebb
+)
+
+type (
+	// Harmonic data for a tidal current station
+	CurrentHarmonics struct {
+		FloodDirection float64               `json:"flood_direction"`         // degrees true
+		EbbDirection   *float64              `json:"ebb_direction,omitempty"` // degrees true; defaults to the opposite of the flood direction
+		Units          SpeedUnit             `json:"units,omitempty"`         // units of the constituent amplitudes; defaults to m/s
+		Constituents   []*CurrentConstituent `json:"constituents"`
+	}
+
+	// A tidal current constituent. Amplitude and phase describe the current along the major axis. If Inclination
+	// is omitted, the major axis is the station's flood/ebb axis (as in NOAA's published constants); otherwise
+	// the constituent is a tidal ellipse, with MinorAmplitude and Inclination giving its shape and orientation.
+	CurrentConstituent struct {
+		HarmonicConstituent
+		MinorAmplitude float64  `json:"minor_amplitude,omitempty"` // positive for counter-clockwise rotation
+		Inclination    *float64 `json:"inclination,omitempty"`     // direction of the major axis, degrees counter-clockwise from east
+	}
+
+	CurrentValue struct {
+		Time      time.Time
+		Speed     float64 // signed speed along the flood/ebb axis; positive for flood, negative for ebb
+		Direction float64 // degrees true that the water is flowing towards
+		East      float64 // eastward component of the current
+		North     float64 // northward component of the current
+		Type      string  // empty for intermediate values, otherwise one of the CURRENT_ event types
+	}
+)
+
+// Sets the speed units used for current predictions. Accepts any spelling known to ParseSpeedUnit; unknown units are
+// reported by Validate
+func WithSpeedUnits(units SpeedUnit) PredictionOpt {
+	return func(p *Prediction) {
+		if parsed, err := ParseSpeedUnit(string(units)); err == nil {
+			units = parsed
+		}
+		p.SpeedUnits = units
+	}
+}
+
+// Calculates tidal currents for the range of the Prediction. Returns an error if the station has no current harmonics.
+func (p *Prediction) PredictCurrents() ([]*CurrentValue, error) {
+	if p.Harmonics.CurrentPredOffsets != nil {
+		values, _, err := p.subordinateCurrents()
+		if err != nil {
+			return nil, err
+		}
+		return p.localizeCurrents(filterCurrents(values, p.Start, p.End)), nil
+	}
+
+	values, err := p.currentsForRange(p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.localizeCurrents(filterCurrents(values, p.Start, p.End)), nil
+}
+
+// Calculates the times of maximum flood, maximum ebb and slack water for the range of the Prediction
+func (p *Prediction) PredictCurrentEvents() ([]*CurrentValue, error) {
+	if p.Harmonics.CurrentPredOffsets != nil {
+		_, events, err := p.subordinateCurrents()
+		if err != nil {
+			return nil, err
+		}
+		return p.localizeCurrents(filterCurrents(events, p.Start, p.End)), nil
+	}
+
+	// pad the range by one step, so that events at the edges can be detected
+	values, err := p.currentsForRange(p.Start.Add(-p.Interval), p.End.Add(p.Interval))
+	if err != nil {
+		return nil, err
+	}
+
+	events := getCurrentEvents(values)
+
+	return p.localizeCurrents(filterCurrents(events, p.Start, p.End)), nil
+}
+
+// Same as PredictCurrentEvents(), but only returns slack water
+func (p *Prediction) PredictSlacks() ([]*CurrentValue, error) {
+	events, err := p.PredictCurrentEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*CurrentValue, 0)
+	for _, ev := range events {
+		if ev.Type == CURRENT_SLACK_BEFORE_FLOOD || ev.Type == CURRENT_SLACK_BEFORE_EBB {
+			results = append(results, ev)
+		}
+	}
+	return results, nil
+}
+
+func (p *Prediction) currentsForRange(start, end time.Time) ([]*CurrentValue, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if p.Harmonics.Currents == nil || len(p.Harmonics.Currents.Constituents) == 0 {
+		return nil, fmt.Errorf("station has no current harmonics")
+	}
+
+	constituents := p.Harmonics.Currents.harmonicConstituents()
+	harmonicResults := p.harmonicResultsAt(constituents, start)
+	harmonicFactors := p.harmonicFactorsForRange(constituents, start, end)
+
+	values := make([]*CurrentValue, 0)
+	var i int
+	for t := start; t.Before(end); t = t.Add(p.Interval) {
+		elapsedHours := t.Sub(start).Hours()
+		values = append(values, p.getCurrent(t, elapsedHours, harmonicResults, harmonicFactors[i]))
+		i++
+	}
+
+	return values, nil
+}
+
+func (p *Prediction) getCurrent(t time.Time, elapsedHours float64, harmonicResults harmonicResults, harmonicFactors harmonicFactors) *CurrentValue {
+	ch := p.Harmonics.Currents
+
+	// sum the along-axis constituents and the ellipse constituents separately
+	var along, east, north float64
+	for _, c := range ch.Constituents {
+		_, amplitude, f, angle := calcConstituentParts(&c.HarmonicConstituent, elapsedHours, harmonicResults[c.Name], harmonicFactors[c.Name])
+		if c.Inclination == nil {
+			along += amplitude * f * math.Cos(angle)
+			continue
+		}
+
+		major := amplitude * f * math.Cos(angle)
+		minor := c.MinorAmplitude * f * math.Sin(angle)
+		inc := *c.Inclination * astronomy.DEG_TO_RAD
+		east += major*math.Cos(inc) - minor*math.Sin(inc)
+		north += major*math.Sin(inc) + minor*math.Cos(inc)
+	}
+
+	// the signed speed is the ellipse current projected onto the flood axis, plus the along-axis current
+	floodDir := ch.FloodDirection * astronomy.DEG_TO_RAD
+	speed := along + east*math.Sin(floodDir) + north*math.Cos(floodDir)
+
+	// along-axis current flows towards the flood direction, or the ebb direction when negative
+	axisDir := floodDir
+	if along < 0 {
+		axisDir = ch.ebbDirection() * astronomy.DEG_TO_RAD
+	}
+	east += math.Abs(along) * math.Sin(axisDir)
+	north += math.Abs(along) * math.Cos(axisDir)
+
+	return &CurrentValue{
+		Time:      t,
+		Speed:     p.convertSpeed(speed),
+		Direction: modulus(math.Atan2(east, north)*astronomy.RAD_TO_DEG, 360),
+		East:      p.convertSpeed(east),
+		North:     p.convertSpeed(north),
+	}
+}
+
+// Calculates currents for a subordinate station: the reference station's events are shifted & scaled by the
+// offsets, the reference curve is stretched to fit between the corrected events, and the result is resampled
+// at the prediction interval. Returns the resampled values, and the corrected events.
+func (p *Prediction) subordinateCurrents() ([]*CurrentValue, []*CurrentValue, error) {
+	o := p.Harmonics.CurrentPredOffsets
+	floodDir, ebbDir := o.directions(p.Harmonics.Currents)
+
+	// step 1: calculate the reference currents for an extended range, wide enough
+	// to include the events either side of the requested range once offsets are applied
+	refValues, err := p.currentsForRange(p.Start.Add(-24*time.Hour), p.End.Add(24*time.Hour))
+	if err != nil {
+		return nil, nil, err
+	}
+	refEvents := getCurrentEvents(refValues)
+	if len(refEvents) < 2 {
+		return nil, nil, fmt.Errorf("no current events at the reference station")
+	}
+
+	// step 2: apply the offsets to the events
+	events := make([]*CurrentValue, len(refEvents))
+	for i, ev := range refEvents {
+		var offset float64
+		switch ev.Type {
+		case CURRENT_SLACK_BEFORE_FLOOD:
+			offset = o.TimeOffsetMinBeforeFlood
+		case CURRENT_MAX_FLOOD:
+			offset = o.TimeOffsetFlood
+		case CURRENT_SLACK_BEFORE_EBB:
+			offset = o.TimeOffsetMinBeforeEbb
+		case CURRENT_MAX_EBB:
+			offset = o.TimeOffsetEbb
+		}
+		events[i] = newAxisCurrentValue(ev.Time.Add(time.Duration(offset*float64(time.Minute))), o.scaleSpeed(ev.Speed), floodDir, ebbDir)
+		events[i].Type = ev.Type
+
+		// slack has no speed; report the direction the current is turning to
+		switch ev.Type {
+		case CURRENT_SLACK_BEFORE_FLOOD:
+			events[i].Direction = floodDir
+		case CURRENT_SLACK_BEFORE_EBB:
+			events[i].Direction = ebbDir
+		}
+	}
+
+	// step 3: move each reference point to the same proportion of time between the corrected events
+	warped := make([]*CurrentValue, 0, len(refValues))
+	var k int
+	for _, v := range refValues {
+		for k < len(refEvents)-2 && !v.Time.Before(refEvents[k+1].Time) {
+			k++
+		}
+		if v.Time.Before(refEvents[k].Time) || v.Time.After(refEvents[k+1].Time) {
+			continue
+		}
+
+		frac := float64(v.Time.Sub(refEvents[k].Time)) / float64(refEvents[k+1].Time.Sub(refEvents[k].Time))
+		t := events[k].Time.Add(time.Duration(frac * float64(events[k+1].Time.Sub(events[k].Time))))
+
+		// offsets large enough to reorder the events can't be interpolated
+		if len(warped) > 0 && !t.After(warped[len(warped)-1].Time) {
+			continue
+		}
+		warped = append(warped, newAxisCurrentValue(t, o.scaleSpeed(v.Speed), floodDir, ebbDir))
+	}
+
+	// step 4: resample the corrected curve at the prediction interval
+	values := make([]*CurrentValue, 0)
+	var j int
+	for t := p.Start; t.Before(p.End); t = t.Add(p.Interval) {
+		for j < len(warped)-2 && !warped[j+1].Time.After(t) {
+			j++
+		}
+		if j >= len(warped)-1 || warped[j].Time.After(t) || warped[j+1].Time.Before(t) {
+			continue
+		}
+
+		a, b := warped[j], warped[j+1]
+		frac := float64(t.Sub(a.Time)) / float64(b.Time.Sub(a.Time))
+		values = append(values, newAxisCurrentValue(t, a.Speed+frac*(b.Speed-a.Speed), floodDir, ebbDir))
+	}
+
+	return values, events, nil
+}
+
+// creates a current value for a signed speed along the flood/ebb axis
+func newAxisCurrentValue(t time.Time, speed, floodDir, ebbDir float64) *CurrentValue {
+	dir := floodDir
+	if speed < 0 {
+		dir = ebbDir
+	}
+	return &CurrentValue{
+		Time:      t,
+		Speed:     speed,
+		Direction: dir,
+		East:      math.Abs(speed) * math.Sin(dir*astronomy.DEG_TO_RAD),
+		North:     math.Abs(speed) * math.Cos(dir*astronomy.DEG_TO_RAD),
+	}
+}
+
+func (p *Prediction) convertSpeed(val float64) float64 {
+	return p.SpeedUnits.fromMetersPerSecond(val)
+}
+
+// converts result times to the prediction's location, if one is set
+func (p *Prediction) localizeCurrents(results []*CurrentValue) []*CurrentValue {
+	if p.Location == nil {
+		return results
+	}
+	for _, r := range results {
+		r.Time = r.Time.In(p.Location)
+	}
+	return results
+}
+
+// picks out maximum flood, maximum ebb, and slack water; slack times are interpolated between steps
+func getCurrentEvents(values []*CurrentValue) []*CurrentValue {
+	events := make([]*CurrentValue, 0)
+
+	for i := 0; i < len(values)-1; i++ {
+		cur := values[i]
+		next := values[i+1]
+
+		if i > 0 {
+			prev := values[i-1]
+			if cur.Speed > 0 && cur.Speed >= prev.Speed && cur.Speed > next.Speed {
+				cur.Type = CURRENT_MAX_FLOOD
+				events = append(events, cur)
+			} else if cur.Speed < 0 && cur.Speed <= prev.Speed && cur.Speed < next.Speed {
+				cur.Type = CURRENT_MAX_EBB
+				events = append(events, cur)
+			}
+		}
+
+		var slackType string
+		if cur.Speed < 0 && next.Speed >= 0 {
+			slackType = CURRENT_SLACK_BEFORE_FLOOD
+		} else if cur.Speed > 0 && next.Speed <= 0 {
+			slackType = CURRENT_SLACK_BEFORE_EBB
+		}
+		if slackType != "" {
+			frac := cur.Speed / (cur.Speed - next.Speed)
+			events = append(events, &CurrentValue{
+				Time:      cur.Time.Add(time.Duration(frac * float64(next.Time.Sub(cur.Time)))),
+				Direction: next.Direction,
+				Type:      slackType,
+			})
+		}
+	}
+
+	return events
+}
+
+func filterCurrents(values []*CurrentValue, start, end time.Time) []*CurrentValue {
+	filtered := make([]*CurrentValue, 0)
+	for _, v := range values {
+		if !v.Time.Before(start) && v.Time.Before(end) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func (ch *CurrentHarmonics) ebbDirection() float64 {
+	if ch.EbbDirection != nil {
+		return *ch.EbbDirection
+	}
+	return modulus(ch.FloodDirection+180, 360)
+}
+
+// the subordinate station's flood & ebb directions, falling back to the reference station's
+func (o *CurrentPredOffsets) directions(ref *CurrentHarmonics) (float64, float64) {
+	flood := ref.FloodDirection
+	ebb := ref.ebbDirection()
+	if o.FloodDirection != nil {
+		flood = *o.FloodDirection
+		ebb = modulus(flood+180, 360)
+	}
+	if o.EbbDirection != nil {
+		ebb = *o.EbbDirection
+	}
+	return flood, ebb
+}
+
+func (o *CurrentPredOffsets) scaleSpeed(speed float64) float64 {
+	if speed < 0 {
+		return speed * o.SpeedRatioEbb
+	}
+	return speed * o.SpeedRatioFlood
+}
+
+func (ch *CurrentHarmonics) harmonicConstituents() []*HarmonicConstituent {
+	constituents := make([]*HarmonicConstituent, len(ch.Constituents))
+	for i, c := range ch.Constituents {
+		constituents[i] = &c.HarmonicConstituent
+	}
+	return constituents
+}
+
+// Converts the constituent amplitudes from the declared units to m/s, and associates each constituent with its model
+func (ch *CurrentHarmonics) normalize() error {
+	units, err := ParseSpeedUnit(string(ch.Units))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range ch.Constituents {
+		c.Amplitude, _ = units.ToMetersPerSecond(c.Amplitude)
+		c.AmplitudeError, _ = units.ToMetersPerSecond(c.AmplitudeError)
+		c.MinorAmplitude, _ = units.ToMetersPerSecond(c.MinorAmplitude)
+		c.Model = GetConstituentModelForName(c.Name)
+	}
+	ch.Units = BASE_SPEED_UNITS
+
+	return nil
+}
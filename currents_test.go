@@ -0,0 +1,210 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrentEvents(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "current", `{"current_harmonics":{"flood_direction":90,"ebb_direction":280,"units":"kn","constituents":[{"name":"M2","phase_UTC":45,"amplitude":2}]}}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "current")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*25), tides.WithSpeedUnits(tides.UNITS_KNOTS))
+
+	events, err := prediction.PredictCurrentEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 8, len(events))
+
+	// events alternate: flood, slack before ebb, ebb, slack before flood
+	next := map[string]string{
+		tides.CURRENT_MAX_FLOOD:          tides.CURRENT_SLACK_BEFORE_EBB,
+		tides.CURRENT_SLACK_BEFORE_EBB:   tides.CURRENT_MAX_EBB,
+		tides.CURRENT_MAX_EBB:            tides.CURRENT_SLACK_BEFORE_FLOOD,
+		tides.CURRENT_SLACK_BEFORE_FLOOD: tides.CURRENT_MAX_FLOOD,
+	}
+	for i := 1; i < len(events); i++ {
+		assert.Equal(t, next[events[i-1].Type], events[i].Type)
+
+		// a pure M2 current has an event every quarter cycle
+		assert.InDelta(t, 12.42/4, events[i].Time.Sub(events[i-1].Time).Hours(), 0.05)
+	}
+
+	for _, ev := range events {
+		switch ev.Type {
+		case tides.CURRENT_MAX_FLOOD:
+			assert.InDelta(t, 2, ev.Speed, 0.1)
+			assert.InDelta(t, 90, ev.Direction, VAL_TOLERANCE)
+		case tides.CURRENT_MAX_EBB:
+			assert.InDelta(t, -2, ev.Speed, 0.1)
+			assert.InDelta(t, 280, ev.Direction, VAL_TOLERANCE)
+		default:
+			assert.Equal(t, 0.0, ev.Speed)
+		}
+	}
+
+	slacks, err := prediction.PredictSlacks()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(slacks))
+}
+
+func TestCurrentsWithoutHarmonics(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24))
+
+	_, err = prediction.PredictCurrents()
+	assert.Error(t, err)
+	_, err = prediction.PredictCurrentEvents()
+	assert.Error(t, err)
+	_, err = prediction.PredictSlacks()
+	assert.Error(t, err)
+}
+
+func TestCurrentEllipse(t *testing.T) {
+	dir := t.TempDir()
+
+	// a circular, counter-clockwise rotating current with a constant speed of 0.5 m/s
+	writeTestStation(t, dir, "rotary", `{"current_harmonics":{"flood_direction":0,"constituents":[{"name":"M2","phase_UTC":0,"amplitude":0.5,"minor_amplitude":0.5,"inclination":0}]}}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "rotary")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	values, err := har.NewRangePrediction(start, start.Add(time.Hour*13), tides.WithInterval(time.Minute*10)).PredictCurrents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 78, len(values))
+
+	for i, v := range values {
+		assert.InDelta(t, 0.5, math.Hypot(v.East, v.North), 0.03)
+		assert.InDelta(t, v.North, v.Speed, VAL_TOLERANCE)
+
+		// direction turns counter-clockwise, i.e. decreasing degrees true
+		if i > 0 {
+			turn := math.Mod(values[i-1].Direction-v.Direction+360, 360)
+			assert.Greater(t, turn, 0.0)
+			assert.Less(t, turn, 10.0)
+		}
+	}
+}
+
+func TestSubordinateCurrentEvents(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "current", `{"current_harmonics":{"flood_direction":90,"units":"kn","constituents":[{"name":"M2","phase_UTC":45,"amplitude":2},{"name":"S2","phase_UTC":80,"amplitude":0.4}]}}`)
+	writeTestStation(t, dir, "sub", `{"current_pred_offsets":{"ref_station_id":"current","time_offset_min_before_flood":10,"time_offset_flood":30,"time_offset_min_before_ebb":0,"time_offset_ebb":-20,"speed_ratio_flood":0.5,"speed_ratio_ebb":0.8,"flood_direction":45}}`)
+
+	ref, err := tides.LoadHarmonicsFromFile(dir, "current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := tides.LoadHarmonicsFromFile(dir, "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	refEvents, err := ref.NewRangePrediction(start.Add(-time.Hour), end.Add(time.Hour), tides.WithSpeedUnits(tides.UNITS_KNOTS)).PredictCurrentEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subPrediction := sub.NewRangePrediction(start, end, tides.WithSpeedUnits(tides.UNITS_KNOTS))
+	subEvents, err := subPrediction.PredictCurrentEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets := map[string]time.Duration{
+		tides.CURRENT_SLACK_BEFORE_FLOOD: 10 * time.Minute,
+		tides.CURRENT_MAX_FLOOD:          30 * time.Minute,
+		tides.CURRENT_SLACK_BEFORE_EBB:   0,
+		tides.CURRENT_MAX_EBB:            -20 * time.Minute,
+	}
+
+	// match each subordinate event to the reference event it came from
+	var matched int
+	for _, subEv := range subEvents {
+		for _, refEv := range refEvents {
+			if refEv.Type != subEv.Type || math.Abs(refEv.Time.Add(offsets[refEv.Type]).Sub(subEv.Time).Seconds()) > 1 {
+				continue
+			}
+			matched++
+			switch subEv.Type {
+			case tides.CURRENT_MAX_FLOOD:
+				assert.InDelta(t, refEv.Speed*0.5, subEv.Speed, VAL_TOLERANCE)
+				assert.Equal(t, 45.0, subEv.Direction)
+			case tides.CURRENT_MAX_EBB:
+				assert.InDelta(t, refEv.Speed*0.8, subEv.Speed, VAL_TOLERANCE)
+				assert.Equal(t, 225.0, subEv.Direction)
+			}
+		}
+	}
+	assert.Equal(t, len(subEvents), matched)
+	assert.GreaterOrEqual(t, len(subEvents), 7)
+
+	// the resampled curve passes through the corrected events
+	values, err := subPrediction.PredictCurrents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 24*60, len(values))
+	for _, ev := range subEvents {
+		i := int(ev.Time.Sub(start).Minutes())
+		if i < 0 || i >= len(values) {
+			continue
+		}
+		assert.InDelta(t, ev.Speed, values[i].Speed, 0.01)
+	}
+}
+
+func TestSubordinateCurrentDefaultRatios(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "current", `{"current_harmonics":{"flood_direction":90,"units":"kn","constituents":[{"name":"M2","phase_UTC":45,"amplitude":2}]}}`)
+	writeTestStation(t, dir, "sub", `{"current_pred_offsets":{"ref_station_id":"current","time_offset_flood":30,"speed_ratio_ebb":0.5}}`)
+
+	sub, err := tides.LoadHarmonicsFromFile(dir, "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the omitted flood ratio leaves the flood speeds unchanged
+	assert.Equal(t, 1.0, sub.CurrentPredOffsets.SpeedRatioFlood)
+	assert.Equal(t, 0.5, sub.CurrentPredOffsets.SpeedRatioEbb)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	events, err := sub.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithSpeedUnits(tides.UNITS_KNOTS)).PredictCurrentEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range events {
+		switch ev.Type {
+		case tides.CURRENT_MAX_FLOOD:
+			assert.InDelta(t, 2, ev.Speed, 0.1)
+		case tides.CURRENT_MAX_EBB:
+			assert.InDelta(t, -1, ev.Speed, 0.1)
+		}
+	}
+
+	writeTestStation(t, dir, "zero", `{"current_pred_offsets":{"ref_station_id":"current","speed_ratio_flood":0}}`)
+	_, err = tides.LoadHarmonicsFromFile(dir, "zero")
+	assert.Error(t, err)
+}
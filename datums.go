@@ -5,11 +5,41 @@ import (
 	"strings"
 )
 
+const (
+	// Name of the node used for ellipsoid heights when added via AddEllipsoidSeparation
+	ELLIPSOID_DATUM = "ELLIPSOID"
+)
+
 type (
+	// A tidal datum, with its value relative to the station datum
 	Datum struct {
 		Name  string  `json:"name"`
 		Value float64 `json:"value"`
 	}
+
+	// Relates two datums that are not both in the station datum list, e.g. a tidal datum to a geodetic
+	// datum (NAVD88, IGLD), a chart datum, or a local benchmark. Offset is the elevation of From above To,
+	// so a value relative to From is converted to To by adding Offset.
+	DatumLink struct {
+		From   string  `json:"from"`
+		To     string  `json:"to"`
+		Offset float64 `json:"offset"`
+		Source string  `json:"source,omitempty"` // optional note on where the relationship came from
+	}
+
+	// The result of resolving a conversion between two datums
+	DatumConversion struct {
+		From   string
+		To     string
+		Offset float64  // added to a value relative to From to make it relative to To
+		Path   []string // datums visited, starting with From and ending with To
+	}
+
+	datumGraph map[string][]datumEdge
+	datumEdge  struct {
+		to     string
+		offset float64
+	}
 )
 
 func (h *Harmonics) GetDatum(name string) *Datum {
@@ -22,16 +52,152 @@ func (h *Harmonics) GetDatum(name string) *Datum {
 	return nil
 }
 
+// Returns the datum value (stored in meters) converted to the given units
+func (d *Datum) ValueIn(units LengthUnit) (float64, error) {
+	return units.FromMeters(d.Value)
+}
+
+// Adds a link between two datums to the datum graph. Offset is the elevation of `from` above `to`, in meters.
+func (h *Harmonics) AddDatumLink(from, to string, offset float64, source string) {
+	h.DatumLinks = append(h.DatumLinks, &DatumLink{
+		From:   from,
+		To:     to,
+		Offset: offset,
+		Source: source,
+	})
+}
+
+// Adds ellipsoid heights to the datum graph, using a user-supplied separation (geoid height, in meters) between
+// an orthometric datum (e.g. NAVD88) and the ellipsoid, such that ellipsoid height = orthometric height + separation.
+func (h *Harmonics) AddEllipsoidSeparation(orthometricDatum string, separation float64) {
+	h.AddDatumLink(orthometricDatum, ELLIPSOID_DATUM, separation, "ellipsoid separation")
+}
+
+// Converts a value relative to one datum to be relative to another, chaining through
+// geodetic and local datum links where needed
 func (h *Harmonics) DatumConvert(from, to string, val float64) (float64, error) {
-	fromDatum := h.GetDatum(from)
-	if fromDatum == nil {
-		return 0, fmt.Errorf("datum not found: %s", from)
+	conv, err := h.DatumConversion(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return val + conv.Offset, nil
+}
+
+// Finds the shortest chain of known datum relationships between two datums. Station datums are
+// all related to each other directly, and DatumLinks connect them to geodetic or local datums.
+func (h *Harmonics) DatumConversion(from, to string) (*DatumConversion, error) {
+	graph, names := h.datumGraph()
+
+	fromKey := strings.ToUpper(from)
+	toKey := strings.ToUpper(to)
+	if _, ok := graph[fromKey]; !ok {
+		return nil, fmt.Errorf("datum not found: %s", from)
+	}
+	if _, ok := graph[toKey]; !ok {
+		return nil, fmt.Errorf("datum not found: %s", to)
+	}
+
+	// breadth-first search, so that the path with the fewest hops is used
+	type visit struct {
+		prev   string
+		offset float64
+	}
+	visited := map[string]visit{fromKey: {}}
+	queue := []string{fromKey}
+	for len(queue) > 0 && queue[0] != toKey {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, edge := range graph[cur] {
+			if _, ok := visited[edge.to]; ok {
+				continue
+			}
+			visited[edge.to] = visit{prev: cur, offset: visited[cur].offset + edge.offset}
+			queue = append(queue, edge.to)
+		}
 	}
 
-	toDatum := h.GetDatum(to)
-	if toDatum == nil {
-		return 0, fmt.Errorf("datum not found: %s", to)
+	v, ok := visited[toKey]
+	if !ok {
+		return nil, fmt.Errorf("no conversion path between datums: %s -> %s", from, to)
 	}
 
-	return val + fromDatum.Value - toDatum.Value, nil
+	// walk back to build the path
+	path := []string{names[toKey]}
+	for cur := toKey; cur != fromKey; cur = visited[cur].prev {
+		path = append([]string{names[visited[cur].prev]}, path...)
+	}
+
+	return &DatumConversion{
+		From:   names[fromKey],
+		To:     names[toKey],
+		Offset: v.offset,
+		Path:   path,
+	}, nil
+}
+
+// Lists the names of all datums in the datum graph, station datums first
+func (h *Harmonics) DatumNames() []string {
+	result := make([]string, 0, len(h.Datums))
+	for _, d := range h.Datums {
+		result = append(result, d.Name)
+	}
+	for _, l := range h.DatumLinks {
+		for _, n := range []string{l.From, l.To} {
+			if !containsFold(result, n) {
+				result = append(result, n)
+			}
+		}
+	}
+	return result
+}
+
+func (c *DatumConversion) String() string {
+	return fmt.Sprintf("%s (%+f m)", strings.Join(c.Path, " -> "), c.Offset)
+}
+
+// builds the graph of datums, keyed by upper-cased name; also returns the display name for each key
+func (h *Harmonics) datumGraph() (datumGraph, map[string]string) {
+	graph := datumGraph{}
+	names := map[string]string{}
+
+	addNode := func(name string) string {
+		key := strings.ToUpper(name)
+		if _, ok := names[key]; !ok {
+			names[key] = name
+			graph[key] = nil
+		}
+		return key
+	}
+
+	// station datums are all relative to the station datum, so each is directly related to every other
+	for _, a := range h.Datums {
+		aKey := addNode(a.Name)
+		for _, b := range h.Datums {
+			bKey := addNode(b.Name)
+			if aKey == bKey {
+				continue
+			}
+			graph[aKey] = append(graph[aKey], datumEdge{to: bKey, offset: a.Value - b.Value})
+		}
+	}
+
+	// links are usable in both directions
+	for _, l := range h.DatumLinks {
+		fromKey := addNode(l.From)
+		toKey := addNode(l.To)
+		graph[fromKey] = append(graph[fromKey], datumEdge{to: toKey, offset: l.Offset})
+		graph[toKey] = append(graph[toKey], datumEdge{to: fromKey, offset: -l.Offset})
+	}
+
+	return graph, names
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
 }
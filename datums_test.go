@@ -0,0 +1,79 @@
+package tides_test
+
+import (
+	"testing"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDatumHarmonics() *tides.Harmonics {
+	return &tides.Harmonics{
+		Datums: []*tides.Datum{
+			{Name: "MHHW", Value: 5.0},
+			{Name: "MTL", Value: 3.0},
+			{Name: "MLLW", Value: 1.0},
+		},
+		DatumLinks: []*tides.DatumLink{
+			{From: "NAVD88", To: "MLLW", Offset: 0.5},
+			{From: "BM-1", To: "NAVD88", Offset: 4.0},
+		},
+	}
+}
+
+func TestDatumConvertStationDatums(t *testing.T) {
+	har := testDatumHarmonics()
+
+	v, err := har.DatumConvert("MTL", "mllw", 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2.0, v, VAL_TOLERANCE)
+
+	conv, err := har.DatumConversion("MTL", "MLLW")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MTL", "MLLW"}, conv.Path)
+}
+
+func TestDatumConvertChained(t *testing.T) {
+	har := testDatumHarmonics()
+
+	// MTL is 2.0 above MLLW, and MLLW is 0.5 below NAVD88
+	conv, err := har.DatumConversion("MTL", "NAVD88")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MTL", "MLLW", "NAVD88"}, conv.Path)
+	assert.InDelta(t, 1.5, conv.Offset, VAL_TOLERANCE)
+
+	// and the benchmark is 4.0 above NAVD88
+	v, err := har.DatumConvert("MHHW", "BM-1", 1.0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.0+4.0-0.5-4.0, v, VAL_TOLERANCE)
+
+	// round trip
+	back, err := har.DatumConvert("BM-1", "MHHW", v)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.0, back, VAL_TOLERANCE)
+}
+
+func TestDatumConvertEllipsoid(t *testing.T) {
+	har := testDatumHarmonics()
+	har.AddEllipsoidSeparation("NAVD88", -23.0)
+
+	conv, err := har.DatumConversion("MLLW", tides.ELLIPSOID_DATUM)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MLLW", "NAVD88", tides.ELLIPSOID_DATUM}, conv.Path)
+
+	// MLLW is 0.5 below NAVD88, which is 23 below the ellipsoid
+	v, err := har.DatumConvert("MLLW", tides.ELLIPSOID_DATUM, 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, -0.5-23.0, v, VAL_TOLERANCE)
+}
+
+func TestDatumConvertErrors(t *testing.T) {
+	har := testDatumHarmonics()
+	har.AddDatumLink("IGLD85", "LWD", 0.2, "")
+
+	_, err := har.DatumConvert("MTL", "NGVD29", 0)
+	assert.Error(t, err)
+
+	_, err = har.DatumConvert("MTL", "IGLD85", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+const (
+	SPECIES_LONG_PERIOD   ConstituentSpecies = "long-period"
+	SPECIES_DIURNAL       ConstituentSpecies = "diurnal"
+	SPECIES_SEMIDIURNAL   ConstituentSpecies = "semidiurnal"
+	SPECIES_SHALLOW_WATER ConstituentSpecies = "shallow-water" // terdiurnal & faster: M3, and the overtides & compound tides
+)
+
+// The constituent species, in order of speed
+var CONSTITUENT_SPECIES = []ConstituentSpecies{SPECIES_LONG_PERIOD, SPECIES_DIURNAL, SPECIES_SEMIDIURNAL, SPECIES_SHALLOW_WATER}
+
+type (
+	ConstituentSpecies string
+
+	// A predicted level, broken down into the contribution of each constituent
+	DecomposedValue struct {
+		Time         time.Time
+		Level        float64                        // the total level, as from Predict
+		Constituents map[string]float64             // the contribution of each of the station's constituents
+		Species      map[ConstituentSpecies]float64 // the contributions summed by species
+		Offset       float64                        // datum, mean sea level & meteorological adjustments; Level less the constituents
+	}
+)
+
+// Calculates the levels for the range of the Prediction, along with the contribution of each constituent & species
+// to each. Contributions are in the prediction's units, without the datum offset. Only available for reference
+// stations, since subordinate offsets are applied to the total.
+func (p *Prediction) PredictDecomposition() ([]*DecomposedValue, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	if p.Harmonics.TidePredOffsets != nil {
+		return nil, fmt.Errorf("decomposition is not available for subordinate stations")
+	}
+
+	w := p.working()
+	err := w.resolveDatumOffset()
+	if err != nil {
+		return nil, fmt.Errorf("error converting datum: %s", err)
+	}
+
+	w.extendedStart = w.Start
+	constituents := w.Harmonics.Constituents
+	harmonicResults := w.harmonicResultsAt(constituents, w.Start)
+	harmonicFactors := w.harmonicFactorsForRange(constituents, w.Start, w.End)
+
+	values := make([]*DecomposedValue, 0, len(harmonicFactors))
+	var i int
+	for t := w.Start; t.Before(w.End); t = t.Add(w.Interval) {
+		elapsedHours := t.Sub(w.Start).Hours()
+		value := &DecomposedValue{
+			Time:         t,
+			Level:        w.getLevel(elapsedHours, harmonicResults, harmonicFactors[i]),
+			Constituents: map[string]float64{},
+			Species:      map[ConstituentSpecies]float64{},
+		}
+		value.Level += w.nonTidalLevelAt(t)
+
+		value.Offset = value.Level
+		for _, c := range constituents {
+			result := harmonicResults[c.Name]
+			_, amplitude, f, angle := calcConstituentParts(c, elapsedHours, result, harmonicFactors[i][c.Name])
+			contribution := w.toOutputUnits(amplitude * f * math.Cos(angle))
+			value.Constituents[c.Name] = contribution
+			value.Species[speciesForSpeed(result.speed)] += contribution
+			value.Offset -= contribution
+		}
+
+		values = append(values, value)
+		i++
+	}
+
+	if w.Location != nil {
+		for _, v := range values {
+			v.Time = v.Time.In(w.Location)
+		}
+	}
+	return values, nil
+}
+
+// the species for a speed in radians per hour: the nearest number of cycles per day
+func speciesForSpeed(speed float64) ConstituentSpecies {
+	switch cycles := math.Round(speed * astronomy.RAD_TO_DEG / 15); {
+	case cycles < 1:
+		return SPECIES_LONG_PERIOD
+	case cycles < 2:
+		return SPECIES_DIURNAL
+	case cycles < 3:
+		return SPECIES_SEMIDIURNAL
+	default:
+		return SPECIES_SHALLOW_WATER
+	}
+}
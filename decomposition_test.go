@@ -0,0 +1,92 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredictDecomposition(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*30), tides.WithDatum("MLLW"), tides.WithUnits(tides.UNITS_FEET))
+	values, err := prediction.PredictDecomposition()
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected := prediction.Predict()
+	if !assert.Len(t, values, len(expected)) {
+		return
+	}
+
+	for i, v := range values {
+		assert.Equal(t, expected[i].Time, v.Time)
+		assert.InDelta(t, expected[i].Level, v.Level, 1e-6, v.Time)
+		assert.Len(t, v.Constituents, len(har.Constituents))
+
+		// the constituents & the species both add up to the total, less the datum offset
+		var constituents, species float64
+		for _, c := range v.Constituents {
+			constituents += c
+		}
+		for _, s := range v.Species {
+			species += s
+		}
+		assert.InDelta(t, v.Level, constituents+v.Offset, 1e-9)
+		assert.InDelta(t, constituents, species, 1e-9)
+	}
+
+	// Seattle's range is mostly semidiurnal, with a large diurnal part; M2 alone is over 3ft
+	var m2, semidiurnal, diurnal, shallow float64
+	for _, v := range values {
+		m2 = math.Max(m2, v.Constituents["M2"])
+		semidiurnal = math.Max(semidiurnal, v.Species[tides.SPECIES_SEMIDIURNAL])
+		diurnal = math.Max(diurnal, v.Species[tides.SPECIES_DIURNAL])
+		shallow = math.Max(shallow, v.Species[tides.SPECIES_SHALLOW_WATER])
+	}
+	assert.Greater(t, semidiurnal, diurnal)
+	assert.Greater(t, diurnal, shallow)
+	assert.Greater(t, m2, 3.0)
+}
+
+func TestPredictDecompositionSpecies(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "species", `{"harmonic_constituents":[
+		{"name":"MF","phase_UTC":10,"amplitude":0.1},
+		{"name":"O1","phase_UTC":20,"amplitude":0.2},
+		{"name":"S2","phase_UTC":30,"amplitude":0.3},
+		{"name":"M3","phase_UTC":40,"amplitude":0.04},
+		{"name":"M4","phase_UTC":50,"amplitude":0.05}
+	],"datums":[]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "species")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	values, err := har.NewRangePrediction(start, start.Add(time.Hour*6), tides.WithInterval(time.Hour)).PredictDecomposition()
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, v := range values {
+		assert.InDelta(t, v.Constituents["MF"], v.Species[tides.SPECIES_LONG_PERIOD], 1e-12)
+		assert.InDelta(t, v.Constituents["O1"], v.Species[tides.SPECIES_DIURNAL], 1e-12)
+		assert.InDelta(t, v.Constituents["S2"], v.Species[tides.SPECIES_SEMIDIURNAL], 1e-12)
+		assert.InDelta(t, v.Constituents["M3"]+v.Constituents["M4"], v.Species[tides.SPECIES_SHALLOW_WATER], 1e-12)
+	}
+}
+
+func TestPredictDecompositionSubordinate(t *testing.T) {
+	har := loadTestSubordinate(t)
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	_, err := har.NewRangePrediction(start, start.Add(time.Hour)).PredictDecomposition()
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package tides
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// Settings for picking highs & lows out of the predicted levels; the zero value reports every turning point
+	ExtremaConfig struct {
+		// A high & low closer in level than this (in the prediction's units) are a wiggle, not a tide, and are dropped
+		MinProminence float64
+		// A high & low closer in time than this are dropped, whatever their levels
+		MinSeparation time.Duration
+		// Two highs (or lows) are a double high (or low) water when the dip between them is less than this fraction
+		// of the range to the lows (or highs) either side; the dip is then not reported. Zero disables detection.
+		DoubleRatio float64
+		// If set, the water is standing while it changes by less than this many units per hour, and each extrema
+		// reports the stand around it
+		StandRate float64
+	}
+
+	// A near-flat period around a high or low
+	Stand struct {
+		Start time.Time
+		End   time.Time
+	}
+)
+
+// Sets how highs & lows are picked out of the predicted levels
+func WithExtremaConfig(config *ExtremaConfig) PredictionOpt {
+	return func(p *Prediction) {
+		p.Extrema = config
+	}
+}
+
+// The length of the stand
+func (s *Stand) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// drops insignificant high & low pairs, and then merges double highs & lows; returns the remaining extrema
+func (p *Prediction) filterExtrema(extrema []*PredictionValue) []*PredictionValue {
+	if p.Extrema == nil {
+		return extrema
+	}
+
+	// repeatedly remove the smallest insignificant swing, so that wiggles are removed before the tides around them
+	for {
+		smallest := -1
+		for i := 0; i < len(extrema)-1; i++ {
+			if !p.Extrema.insignificant(extrema[i], extrema[i+1]) {
+				continue
+			}
+			if smallest < 0 || swing(extrema[i], extrema[i+1]) < swing(extrema[smallest], extrema[smallest+1]) {
+				smallest = i
+			}
+		}
+		if smallest < 0 {
+			break
+		}
+		extrema = removeSwing(extrema, smallest)
+	}
+
+	if p.Extrema.DoubleRatio > 0 {
+		extrema = p.Extrema.mergeDoubles(extrema)
+	}
+	return extrema
+}
+
+// whether a pair of neighbouring extrema is too small or too close to be a tide
+func (c *ExtremaConfig) insignificant(a, b *PredictionValue) bool {
+	return swing(a, b) < c.MinProminence || b.Time.Sub(a.Time) < c.MinSeparation
+}
+
+// removes the swing between extrema i & i+1; of each of them and its same-type neighbour, the more extreme is kept
+// in the neighbour's place, so the highs & lows still alternate
+func removeSwing(extrema []*PredictionValue, i int) []*PredictionValue {
+	a, b := extrema[i], extrema[i+1]
+
+	// at the ends of the range, just drop the outermost extrema
+	if i == 0 {
+		a.Type = ""
+		return extrema[1:]
+	}
+	if i+1 == len(extrema)-1 {
+		b.Type = ""
+		return extrema[:i+1]
+	}
+
+	// if both would be kept, they'd swap order; keep the neighbours instead
+	before, after := extrema[i-1], extrema[i+2]
+	keepB, keepA := moreExtreme(b, before), moreExtreme(a, after)
+	if keepA && keepB {
+		keepA, keepB = false, false
+	}
+	if keepB {
+		before.Type = ""
+		extrema[i-1] = b
+	} else {
+		b.Type = ""
+	}
+	if keepA {
+		after.Type = ""
+		extrema[i+2] = a
+	} else {
+		a.Type = ""
+	}
+
+	return append(extrema[:i:i], extrema[i+2:]...)
+}
+
+// finds highs (or lows) separated by a shallow dip; the dip is dropped & both are marked as double
+func (c *ExtremaConfig) mergeDoubles(extrema []*PredictionValue) []*PredictionValue {
+	merged := make([]*PredictionValue, 0, len(extrema))
+	for i := 0; i < len(extrema); i++ {
+		ex := extrema[i]
+		if i > 0 && i < len(extrema)-1 && merged[len(merged)-1] == extrema[i-1] {
+			a, b := extrema[i-1], extrema[i+1]
+
+			// the dip below the higher of the pair (or rise above the lower), relative to the range either side; a
+			// turning point just after a high or low, but far from the next, is a wiggle on the tide, not a double
+			dip := math.Max(swing(a, ex), swing(ex, b))
+			var ranges []float64
+			if i >= 2 {
+				ranges = append(ranges, swing(extrema[i-2], a))
+			}
+			if i <= len(extrema)-3 {
+				ranges = append(ranges, swing(b, extrema[i+2]))
+			}
+			if len(ranges) > 0 && dip < c.DoubleRatio*mean(ranges) {
+				a.Double, b.Double = true, true
+				ex.Type = ""
+				continue
+			}
+		}
+		merged = append(merged, ex)
+	}
+	return merged
+}
+
+// marks the stand around each extrema: the points either side where the level changes slower than the stand rate
+func (p *Prediction) markStands(predictions []*PredictionValue) {
+	if p.Extrema == nil || p.Extrema.StandRate <= 0 {
+		return
+	}
+
+	rate := func(i int) float64 {
+		return math.Abs((predictions[i+1].Level - predictions[i].Level) / predictions[i+1].Time.Sub(predictions[i].Time).Hours())
+	}
+	for i, r := range predictions {
+		if r.Type != "H" && r.Type != "L" {
+			continue
+		}
+		start, end := i, i
+		for start > 0 && rate(start-1) < p.Extrema.StandRate {
+			start--
+		}
+		for end < len(predictions)-1 && rate(end) < p.Extrema.StandRate {
+			end++
+		}
+		r.Stand = &Stand{Start: predictions[start].Time, End: predictions[end].Time}
+	}
+}
+
+// classifies the extrema, & marks the stands from the levels around them
+func (p *Prediction) annotateExtrema(extrema []*PredictionValue, predictions []*PredictionValue) {
+	p.classifyExtrema(extrema)
+	p.markStands(predictions)
+}
+
+func swing(a, b *PredictionValue) float64 {
+	return math.Abs(a.Level - b.Level)
+}
+
+// whether a is further from the mean than b, in the direction of its type
+func moreExtreme(a, b *PredictionValue) bool {
+	if a.Type == "H" {
+		return a.Level > b.Level
+	}
+	return a.Level < b.Level
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,145 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+// M2 with a strong M4 in phase, which splits each low water in two (like Southampton's double highs, upside down)
+func loadDoubleLowStation(t *testing.T) *tides.Harmonics {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "double", `{"harmonic_constituents":[
+		{"name":"M2","phase_UTC":0,"amplitude":1.0},
+		{"name":"M4","phase_UTC":0,"amplitude":0.3}
+	],"datums":[]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "double")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return har
+}
+
+func TestExtremaUnfiltered(t *testing.T) {
+	har := loadDoubleLowStation(t)
+
+	// without a config, the small rise between the two lows is reported as a high
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 0, 3), tides.WithInterval(time.Minute*6)).PredictExtrema()
+	var highs, lows int
+	for _, ex := range extrema {
+		assert.False(t, ex.Double)
+		assert.Nil(t, ex.Stand)
+		if ex.Type == "H" {
+			highs++
+		} else {
+			lows++
+		}
+	}
+	assert.InDelta(t, 12, highs, 1)
+	assert.InDelta(t, 12, lows, 1)
+}
+
+func TestExtremaProminence(t *testing.T) {
+	har := loadDoubleLowStation(t)
+
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 0, 3),
+		tides.WithInterval(time.Minute*6),
+		tides.WithExtremaConfig(&tides.ExtremaConfig{MinProminence: 0.1}),
+	).PredictExtrema()
+	if !assert.NotEmpty(t, extrema) {
+		return
+	}
+
+	// one high & one low per M2 cycle, alternating, with each high well above each low
+	assert.InDelta(t, 6, len(extrema)/2, 1)
+	for i, ex := range extrema {
+		if ex.Type == "H" {
+			assert.Greater(t, ex.Level, 1.0, ex.Time)
+		} else {
+			assert.Less(t, ex.Level, -0.5, ex.Time)
+		}
+		if i > 0 {
+			assert.NotEqual(t, extrema[i-1].Type, ex.Type, ex.Time)
+			assert.True(t, ex.Time.After(extrema[i-1].Time))
+		}
+	}
+}
+
+func TestExtremaSeparation(t *testing.T) {
+	har := loadDoubleLowStation(t)
+
+	// the two lows are about two hours apart, so a three hour separation leaves just one of them
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 0, 3),
+		tides.WithInterval(time.Minute*6),
+		tides.WithExtremaConfig(&tides.ExtremaConfig{MinSeparation: 3 * time.Hour}),
+	).PredictExtrema()
+	for i := 1; i < len(extrema); i++ {
+		assert.GreaterOrEqual(t, extrema[i].Time.Sub(extrema[i-1].Time), 3*time.Hour)
+		assert.NotEqual(t, extrema[i-1].Type, extrema[i].Type)
+	}
+}
+
+func TestExtremaDoubles(t *testing.T) {
+	har := loadDoubleLowStation(t)
+
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 0, 3),
+		tides.WithInterval(time.Minute*6),
+		tides.WithExtremaConfig(&tides.ExtremaConfig{DoubleRatio: 0.2}),
+	).PredictExtrema()
+	if !assert.NotEmpty(t, extrema) {
+		return
+	}
+
+	// the rise between the lows is dropped, leaving consecutive pairs of lows marked as double
+	var doubles int
+	for i, ex := range extrema {
+		if ex.Type == "H" {
+			assert.False(t, ex.Double, ex.Time)
+			continue
+		}
+		if ex.Double {
+			doubles++
+		}
+		if i > 0 && extrema[i-1].Type == "L" {
+			assert.True(t, ex.Double, ex.Time)
+			assert.True(t, extrema[i-1].Double, extrema[i-1].Time)
+			assert.Less(t, ex.Time.Sub(extrema[i-1].Time), 4*time.Hour)
+		}
+	}
+	assert.InDelta(t, 12, doubles, 2)
+}
+
+func TestExtremaStands(t *testing.T) {
+	har := loadDoubleLowStation(t)
+
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 0, 3),
+		tides.WithInterval(time.Minute*6),
+		tides.WithExtremaConfig(&tides.ExtremaConfig{MinProminence: 0.1, StandRate: 0.1}),
+	).PredictExtrema()
+	if !assert.NotEmpty(t, extrema) {
+		return
+	}
+
+	for _, ex := range extrema {
+		if !assert.NotNil(t, ex.Stand, ex.Time) {
+			continue
+		}
+		assert.False(t, ex.Stand.Start.After(ex.Time))
+		assert.False(t, ex.Stand.End.Before(ex.Time))
+
+		// the lows are flattened by M4, so stand much longer than the peaked highs
+		if ex.Type == "L" {
+			assert.Greater(t, ex.Stand.Duration(), 2*time.Hour, ex.Time)
+		} else {
+			assert.Less(t, ex.Stand.Duration(), time.Hour, ex.Time)
+		}
+	}
+}
@@ -12,9 +12,19 @@ const (
 
 type (
 	Harmonics struct {
-		Constituents    []*HarmonicConstituent
-		Datums          []*Datum
-		TidePredOffsets *TidePredOffsets
+		Constituents       []*HarmonicConstituent
+		Datums             []*Datum
+		DatumLinks         []*DatumLink
+		TidePredOffsets    *TidePredOffsets
+		Currents           *CurrentHarmonics
+		CurrentPredOffsets *CurrentPredOffsets
+		Name               string
+		Latitude           float64 // degrees north
+		Longitude          float64 // degrees east; negative west of Greenwich
+		Timezone           string  // IANA timezone name of the station, e.g. "America/Los_Angeles"
+		SeaLevelTrend      *SeaLevelTrend
+		SeasonalMSL        *SeasonalMSL
+		NodeFactors        NodeFactorMethod // formulation of the node factors; defaults to Schureman
 	}
 	HarmonicConstituent struct {
 		Name       string                   `json:"name"`
@@ -23,6 +33,9 @@ type (
 		PhaseLocal float64                  `json:"phase_local"` // TODO how/hwere is this used
 		Amplitude  float64                  `json:"amplitude"`
 		Speed      float64                  `json:"speed"` // TODO how/hwere is this used
+		// optional standard errors, e.g. from a harmonic analysis
+		AmplitudeError float64 `json:"amplitude_error,omitempty"`
+		PhaseError     float64 `json:"phase_error,omitempty"` // in degrees
 	}
 	harmonicConstituentModel interface {
 		GetName() string
@@ -30,6 +43,8 @@ type (
 		Value(*astronomy.Astro) float64
 		NodeFactor(*astronomy.Astro) float64
 		FormFactor(*astronomy.Astro) float64
+		ForemanNodeFactor(*astronomy.Astro) float64
+		ForemanFormFactor(*astronomy.Astro) float64
 	}
 
 	harmonicResults map[string]harmonicResult
@@ -48,11 +63,10 @@ type (
 // Creates a new Prediction struct for a date range with the given start and end times. Optionally accepts PredictionOpts
 func (h *Harmonics) NewRangePrediction(start, end time.Time, opts ...PredictionOpt) *Prediction {
 	p := &Prediction{
-		Start:           start,
-		End:             end,
-		Interval:        DEFAULT_PREDICTION_INTERVAL,
-		Harmonics:       h,
-		extendedResults: make([]*PredictionValue, 0),
+		Start:     start,
+		End:       end,
+		Interval:  DEFAULT_PREDICTION_INTERVAL,
+		Harmonics: h,
 	}
 
 	for _, opt := range opts {
@@ -65,11 +79,10 @@ func (h *Harmonics) NewRangePrediction(start, end time.Time, opts ...PredictionO
 // Creates a new Prediction struct for a single point in time. Optionally accepts PredictionOpts
 func (h *Harmonics) NewTimePrediction(t time.Time, opts ...PredictionOpt) *Prediction {
 	p := &Prediction{
-		Start:           t,
-		End:             t,
-		Interval:        DEFAULT_PREDICTION_INTERVAL,
-		Harmonics:       h,
-		extendedResults: make([]*PredictionValue, 0),
+		Start:     t,
+		End:       t,
+		Interval:  DEFAULT_PREDICTION_INTERVAL,
+		Harmonics: h,
 	}
 
 	for _, opt := range opts {
@@ -79,14 +92,11 @@ func (h *Harmonics) NewTimePrediction(t time.Time, opts ...PredictionOpt) *Predi
 	return p
 }
 
-func harmonicResultsAtTime(constituents []*HarmonicConstituent, t time.Time) harmonicResults {
+func harmonicResultsAtTime(constituents []*HarmonicConstituent, astro *astronomy.Astro) harmonicResults {
 
 	// Create maps to store base values and speeds for each constituent.
 	result := harmonicResults{}
 
-	// Initialize the starting astronomical conditions based on the prediction start time.
-	astro := &astronomy.Astro{Time: t}
-
 	// Iterate over each constituent to calculate and store their base value and speed at the start time.
 	for _, constituent := range constituents {
 		value := constituent.Model.Value(astro)
@@ -100,17 +110,16 @@ func harmonicResultsAtTime(constituents []*HarmonicConstituent, t time.Time) har
 	return result
 }
 
-func harmonicFactorsAtTime(constituents []*HarmonicConstituent, t time.Time) harmonicFactors {
+func harmonicFactorsAtTime(constituents []*HarmonicConstituent, stepAstro *astronomy.Astro, method NodeFactorMethod) harmonicFactors {
 
 	factors := harmonicFactors{}
 
-	stepAstro := &astronomy.Astro{Time: t}
-
 	// Calculate node and form factors for each constituent at this time step.
 	// Values are adjusted to ensure they fall within the [0, 360) range and converted to radians as needed.
 	for _, constituent := range constituents {
-		nodeFactor := modulus(constituent.Model.NodeFactor(stepAstro), 360)
-		formFactor := modulus(constituent.Model.FormFactor(stepAstro), 360)
+		u, f := nodeFactorFuncs(constituent.Model, method)
+		nodeFactor := modulus(u(stepAstro), 360)
+		formFactor := modulus(f(stepAstro), 360)
 
 		factors[constituent.Name] = harmonicFactor{
 			node: astronomy.DEG_TO_RAD * nodeFactor,
@@ -120,3 +129,11 @@ func harmonicFactorsAtTime(constituents []*HarmonicConstituent, t time.Time) har
 
 	return factors
 }
+
+// the functions for u & f of a constituent, in the given formulation
+func nodeFactorFuncs(model harmonicConstituentModel, method NodeFactorMethod) (u, f func(*astronomy.Astro) float64) {
+	if method == NODE_FACTORS_FOREMAN {
+		return model.ForemanNodeFactor, model.ForemanFormFactor
+	}
+	return model.NodeFactor, model.FormFactor
+}
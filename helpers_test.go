@@ -0,0 +1,56 @@
+package tides_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryan-lang/tides"
+)
+
+func writeTestStation(t *testing.T, dir, stationId, doc string) {
+	err := os.WriteFile(filepath.Join(dir, stationId+".json"), []byte(doc), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loads a subordinate station of 9447130, from a copy of the reference station's data
+func loadTestSubordinate(t *testing.T) *tides.Harmonics {
+	dir := t.TempDir()
+	ref, err := os.ReadFile(filepath.Join("data", "9447130.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestStation(t, dir, "9447130", string(ref))
+	writeTestStation(t, dir, "subordinate", `{"tide_pred_offsets":{"ref_station_id":"9447130",
+		"height_offset_high_tide":1.1,"height_offset_low_tide":0.9,"time_offset_high_tide":20,"time_offset_low_tide":45},
+		"datums":[]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "subordinate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return har
+}
+
+func loadUncertaintyStation(t *testing.T) *tides.Harmonics {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "errors", `{"harmonic_constituents":[{"name":"M2","phase_UTC":10.6,"amplitude":1.0,"amplitude_error":0.02,"phase_error":2.0}]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return har
+}
+
+// skips the test when the station's data hasn't been downloaded into ./data
+func skipWithoutStation(t *testing.T, stationId string) {
+	_, err := os.Stat(filepath.Join("data", stationId+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("no data for station %s; download it with `tides download noaaStation --station-id %s`", stationId, stationId)
+	}
+}
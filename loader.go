@@ -12,9 +12,20 @@ import (
 type (
 	// Represents the expected schema of the json file containing station data.
 	StationDocument struct {
+		Name                 string                 `json:"name,omitempty"`
+		Latitude             float64                `json:"latitude,omitempty"`  // degrees north
+		Longitude            float64                `json:"longitude,omitempty"` // degrees east; negative west of Greenwich
 		HarmonicConstituents []*HarmonicConstituent `json:"harmonic_constituents,omitempty"`
 		Datums               []*Datum               `json:"datums"`
+		DatumLinks           []*DatumLink           `json:"datum_links,omitempty"`
 		TidePredOffsets      *TidePredOffsets       `json:"tide_pred_offsets,omitempty"`
+		CurrentHarmonics     *CurrentHarmonics      `json:"current_harmonics,omitempty"`
+		CurrentPredOffsets   *CurrentPredOffsets    `json:"current_pred_offsets,omitempty"`
+		Units                LengthUnit             `json:"units,omitempty"`    // units of amplitudes & datum values; defaults to meters
+		Timezone             string                 `json:"timezone,omitempty"` // IANA timezone name of the station
+		SeaLevelTrend        *SeaLevelTrend         `json:"sea_level_trend,omitempty"`
+		SeasonalMSL          *SeasonalMSL           `json:"seasonal_msl,omitempty"`
+		NodeFactors          NodeFactorMethod       `json:"node_factors,omitempty"` // formulation the constituents were analyzed with; defaults to schureman
 	}
 )
 
@@ -38,7 +49,57 @@ func LoadHarmonicsFromFile(dataDir, stationId string) (*Harmonics, error) {
 		return nil, err
 	}
 
+	// everything is stored internally in meters
+	err = doc.normalizeUnits()
+	if err != nil {
+		return nil, fmt.Errorf("error reading station units (station=%s): %s", stationId, err)
+	}
+
 	harmonics.Datums = doc.Datums
+	harmonics.DatumLinks = doc.DatumLinks
+
+	harmonics.NodeFactors, err = ParseNodeFactorMethod(string(doc.NodeFactors))
+	if err != nil {
+		return nil, fmt.Errorf("error reading node factors (station=%s): %s", stationId, err)
+	}
+	harmonics.Name = doc.Name
+	harmonics.Latitude = doc.Latitude
+	harmonics.Longitude = doc.Longitude
+	harmonics.Timezone = doc.Timezone
+	harmonics.SeaLevelTrend = doc.SeaLevelTrend
+	harmonics.SeaLevelTrend.sortPoints()
+
+	if doc.SeasonalMSL != nil {
+		err = doc.SeasonalMSL.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("error reading seasonal msl (station=%s): %s", stationId, err)
+		}
+		harmonics.SeasonalMSL = doc.SeasonalMSL
+	}
+
+	// current stations carry their own constituents, in speed units
+	if doc.CurrentHarmonics != nil {
+		err = doc.CurrentHarmonics.normalize()
+		if err != nil {
+			return nil, fmt.Errorf("error reading current harmonics (station=%s): %s", stationId, err)
+		}
+		harmonics.Currents = doc.CurrentHarmonics
+	}
+
+	// if station is a subordinate current station, load the current harmonics from the reference station
+	if doc.CurrentPredOffsets != nil && doc.CurrentPredOffsets.RefStationID != "" {
+		refStation, err := LoadHarmonicsFromFile(dataDir, doc.CurrentPredOffsets.RefStationID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading reference current station harmonics (station=%s): %s", doc.CurrentPredOffsets.RefStationID, err)
+		}
+		if refStation.Currents == nil {
+			return nil, fmt.Errorf("reference station has no current harmonics (station=%s)", doc.CurrentPredOffsets.RefStationID)
+		}
+
+		harmonics.Currents = refStation.Currents
+		harmonics.NodeFactors = refStation.NodeFactors
+		harmonics.CurrentPredOffsets = doc.CurrentPredOffsets
+	}
 	harmonics.TidePredOffsets = doc.TidePredOffsets
 
 	// if station is a subordiante, load the harmonics from the reference station
@@ -49,6 +110,7 @@ func LoadHarmonicsFromFile(dataDir, stationId string) (*Harmonics, error) {
 		}
 
 		harmonics.Constituents = refStation.Constituents
+		harmonics.NodeFactors = refStation.NodeFactors
 	} else {
 		harmonics.Constituents = doc.HarmonicConstituents
 	}
@@ -61,6 +123,47 @@ func LoadHarmonicsFromFile(dataDir, stationId string) (*Harmonics, error) {
 	return harmonics, nil
 }
 
+// Converts the amplitudes, datum values and datum link offsets in the document from the declared units to meters
+func (doc *StationDocument) normalizeUnits() error {
+	units, err := ParseLengthUnit(string(doc.Units))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range doc.HarmonicConstituents {
+		c.Amplitude, _ = units.ToMeters(c.Amplitude)
+		c.AmplitudeError, _ = units.ToMeters(c.AmplitudeError)
+	}
+	for _, d := range doc.Datums {
+		d.Value, _ = units.ToMeters(d.Value)
+	}
+	for _, l := range doc.DatumLinks {
+		l.Offset, _ = units.ToMeters(l.Offset)
+	}
+	if s := doc.SeasonalMSL; s != nil {
+		for i := range s.Monthly {
+			s.Monthly[i], _ = units.ToMeters(s.Monthly[i])
+		}
+		for _, term := range []*SeasonalTerm{s.Annual, s.SemiAnnual} {
+			if term != nil {
+				term.Amplitude, _ = units.ToMeters(term.Amplitude)
+			}
+		}
+	}
+	doc.Units = BASE_UNITS
+
+	return nil
+}
+
+// The names of all constituents with a model, in catalog order
+var CONSTITUENT_NAMES = []string{
+	"Z0", "SA", "SSA", "MM", "MF",
+	"Q1", "O1", "K1", "J1", "M1", "P1", "S1", "OO1",
+	"2N2", "N2", "NU2", "M2", "LAM2", "L2", "T2", "S2", "R2", "K2",
+	"M3",
+	"MSF", "2Q1", "RHO", "MU2", "2SM2", "2MK3", "MK3", "MN4", "M4", "MS4", "S4", "M6", "S6", "M8",
+}
+
 func GetConstituentModelForName(name string) harmonicConstituentModel {
 	switch name {
 	case "Z0":
@@ -0,0 +1,240 @@
+package tides
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+const (
+	// Lowest first; longer exposure breaks ties (the default)
+	LOWS_SORT_LEVEL LowsSort = "level"
+	// Longest exposure within the window first; lower level breaks ties
+	LOWS_SORT_EXPOSURE LowsSort = "exposure"
+	// In time order
+	LOWS_SORT_TIME LowsSort = "time"
+)
+
+type (
+	// How daylight lows are ranked
+	LowsSort string
+
+	// Settings for finding low tides within a daily window
+	DaylightLowsConfig struct {
+		// If WindowEnd is set, lows must fall between these local times of day (as offsets from local midnight)
+		// instead of between sunrise & sunset
+		WindowStart time.Duration
+		WindowEnd   time.Duration
+		Margin      time.Duration // widens the sunrise-sunset window at both ends, e.g. to include twilight
+		Sort        LowsSort      // defaults to LOWS_SORT_LEVEL
+	}
+
+	// A low tide within the daily window, with the period around it when the level is at or below the threshold
+	DaylightLow struct {
+		Low           *PredictionValue
+		WindowStart   time.Time     // start of the window on the day of the low
+		WindowEnd     time.Time     // end of the window on the day of the low
+		ExposureStart time.Time     // when the level falls to the threshold
+		ExposureEnd   time.Time     // when the level rises back above the threshold
+		Exposure      time.Duration // how much of the exposure falls within the window
+	}
+
+	DaylightLowsOpt func(*DaylightLowsConfig)
+)
+
+// Parses a sort order name; empty means level
+func ParseLowsSort(s string) (LowsSort, error) {
+	switch LowsSort(strings.ToLower(strings.TrimSpace(s))) {
+	case "", LOWS_SORT_LEVEL:
+		return LOWS_SORT_LEVEL, nil
+	case LOWS_SORT_EXPOSURE:
+		return LOWS_SORT_EXPOSURE, nil
+	case LOWS_SORT_TIME:
+		return LOWS_SORT_TIME, nil
+	default:
+		return "", fmt.Errorf("unknown sort order: %s", s)
+	}
+}
+
+// Finds lows between these local clock times (e.g. 6h for 06:00), instead of between sunrise & sunset
+func WithLocalWindow(start, end time.Duration) DaylightLowsOpt {
+	return func(c *DaylightLowsConfig) {
+		c.WindowStart = start
+		c.WindowEnd = end
+	}
+}
+
+// Widens the sunrise-sunset window by this much at both ends
+func WithDaylightMargin(margin time.Duration) DaylightLowsOpt {
+	return func(c *DaylightLowsConfig) {
+		c.Margin = margin
+	}
+}
+
+// Sets how the lows are ranked
+func WithLowsSort(s LowsSort) DaylightLowsOpt {
+	return func(c *DaylightLowsConfig) {
+		c.Sort = s
+	}
+}
+
+// Finds the lows at or below maxLevel (in the prediction's datum & units) that fall between sunrise and sunset at the
+// station, or within a custom local window, ranked by how low they are and how long the exposure lasts.
+// Days are taken in the prediction's location, or the station's timezone if none is set; sunrise & sunset
+// require the station's latitude & longitude.
+func (p *Prediction) PredictDaylightLows(maxLevel float64, opts ...DaylightLowsOpt) ([]*DaylightLow, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	config := &DaylightLowsConfig{Sort: LOWS_SORT_LEVEL}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	custom := config.WindowEnd != 0
+	if custom && config.WindowEnd <= config.WindowStart {
+		return nil, fmt.Errorf("window end must be after window start")
+	}
+	if !custom && !p.Harmonics.HasLocation() {
+		return nil, fmt.Errorf("station has no latitude & longitude; use a custom window")
+	}
+
+	loc, err := p.localLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	results := p.Predict()
+	lows := make([]*DaylightLow, 0)
+	days := map[time.Time]*astronomy.DayEvents{}
+
+	for i, r := range results {
+		if r.Type != "L" || r.Level > maxLevel {
+			continue
+		}
+
+		// the window on the local day of the low
+		dayStart, dayEnd := DayBounds(r.Time, loc)
+		var windowStart, windowEnd time.Time
+		if custom {
+			windowStart, windowEnd = localClock(dayStart, config.WindowStart), localClock(dayStart, config.WindowEnd)
+		} else {
+			day, ok := days[dayStart]
+			if !ok {
+				day = astronomy.Day(dayStart, p.Harmonics.Latitude, p.Harmonics.Longitude)
+				days[dayStart] = day
+			}
+			var sunUp bool
+			windowStart, windowEnd, sunUp = daylightWindow(day, dayStart, dayEnd, p.Harmonics.Latitude, p.Harmonics.Longitude)
+			if !sunUp {
+				continue
+			}
+			windowStart, windowEnd = windowStart.Add(-config.Margin), windowEnd.Add(config.Margin)
+		}
+		if r.Time.Before(windowStart) || !r.Time.Before(windowEnd) {
+			continue
+		}
+
+		low := &DaylightLow{
+			Low:         r,
+			WindowStart: windowStart.In(loc),
+			WindowEnd:   windowEnd.In(loc),
+		}
+		low.ExposureStart, low.ExposureEnd = exposure(results, i, maxLevel)
+		low.Exposure = overlap(low.ExposureStart, low.ExposureEnd, windowStart, windowEnd)
+		lows = append(lows, low)
+	}
+
+	sortDaylightLows(lows, config.Sort)
+	return lows, nil
+}
+
+// the time on a day's local clock, so the hours stay the same across daylight saving changes
+func localClock(day time.Time, clock time.Duration) time.Time {
+	y, m, d := day.Date()
+	h, min, sec := int(clock/time.Hour), int(clock%time.Hour/time.Minute), int(clock%time.Minute/time.Second)
+	return time.Date(y, m, d, h, min, sec, int(clock%time.Second), day.Location())
+}
+
+// the part of a day when the sun is up; false if it doesn't rise at all
+func daylightWindow(day *astronomy.DayEvents, dayStart, dayEnd time.Time, lat, lon float64) (time.Time, time.Time, bool) {
+	start, end := dayStart, dayEnd
+	if day.Sunrise != nil {
+		start = *day.Sunrise
+	} else if astronomy.SunAltitude(dayStart, lat, lon) < astronomy.SUN_STANDARD_ALTITUDE {
+		// no sunrise, and the sun is down at the start of the day
+		return start, end, false
+	}
+	if day.Sunset != nil && day.Sunset.After(start) {
+		end = *day.Sunset
+	}
+	return start, end, true
+}
+
+// walks out from a low to where the level crosses the threshold, interpolating between steps
+func exposure(results []*PredictionValue, i int, maxLevel float64) (time.Time, time.Time) {
+	j := i
+	for j > 0 && results[j-1].Level <= maxLevel {
+		j--
+	}
+	start := results[j].Time
+	if j > 0 {
+		start = crossing(results[j-1], results[j], maxLevel)
+	}
+
+	j = i
+	for j < len(results)-1 && results[j+1].Level <= maxLevel {
+		j++
+	}
+	end := results[j].Time
+	if j < len(results)-1 {
+		end = crossing(results[j], results[j+1], maxLevel)
+	}
+	return start, end
+}
+
+// the time between two results at which the level equals the threshold
+func crossing(a, b *PredictionValue, level float64) time.Time {
+	if a.Level == b.Level {
+		return a.Time
+	}
+	frac := (level - a.Level) / (b.Level - a.Level)
+	return a.Time.Add(time.Duration(frac * float64(b.Time.Sub(a.Time)))).Round(time.Second)
+}
+
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	if bStart.After(aStart) {
+		aStart = bStart
+	}
+	if bEnd.Before(aEnd) {
+		aEnd = bEnd
+	}
+	if aEnd.Before(aStart) {
+		return 0
+	}
+	return aEnd.Sub(aStart)
+}
+
+func sortDaylightLows(lows []*DaylightLow, by LowsSort) {
+	sort.SliceStable(lows, func(i, j int) bool {
+		a, b := lows[i], lows[j]
+		switch by {
+		case LOWS_SORT_TIME:
+			return a.Low.Time.Before(b.Low.Time)
+		case LOWS_SORT_EXPOSURE:
+			if a.Exposure != b.Exposure {
+				return a.Exposure > b.Exposure
+			}
+			return a.Low.Level < b.Low.Level
+		default:
+			if a.Low.Level != b.Low.Level {
+				return a.Low.Level < b.Low.Level
+			}
+			return a.Exposure > b.Exposure
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaylightLows(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	start := time.Date(2023, 6, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2023, 7, 1, 0, 0, 0, 0, loc)
+	prediction := har.NewRangePrediction(start, end, tides.WithDatum("MLLW"), tides.WithInterval(time.Minute*6), tides.WithLocation(loc))
+
+	// sunrise & sunset need the station position
+	_, err = prediction.PredictDaylightLows(0)
+	assert.Error(t, err)
+
+	har.Latitude, har.Longitude = 47.6026, -122.3393
+	lows, err := prediction.PredictDaylightLows(0)
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, lows) {
+		return
+	}
+
+	// Seattle's summer lower lows are all in daylight
+	for i, l := range lows {
+		assert.Equal(t, "L", l.Low.Type)
+		assert.LessOrEqual(t, l.Low.Level, 0.0)
+		assert.False(t, l.Low.Time.Before(l.WindowStart))
+		assert.True(t, l.Low.Time.Before(l.WindowEnd))
+		assert.True(t, l.WindowStart.Hour() >= 4 && l.WindowStart.Hour() <= 6, "sunrise %s", l.WindowStart)
+		assert.False(t, l.ExposureStart.After(l.Low.Time))
+		assert.False(t, l.ExposureEnd.Before(l.Low.Time))
+		assert.Greater(t, l.Exposure, time.Duration(0))
+		assert.LessOrEqual(t, l.Exposure, l.ExposureEnd.Sub(l.ExposureStart))
+		if i > 0 {
+			assert.LessOrEqual(t, lows[i-1].Low.Level, l.Low.Level)
+		}
+	}
+
+	// a custom window only returns lows within those hours
+	early, err := prediction.PredictDaylightLows(0, tides.WithLocalWindow(8*time.Hour, 11*time.Hour), tides.WithLowsSort(tides.LOWS_SORT_TIME))
+	if assert.NoError(t, err) && assert.NotEmpty(t, early) {
+		assert.Less(t, len(early), len(lows))
+		for i, l := range early {
+			assert.True(t, l.Low.Time.Hour() >= 8 && l.Low.Time.Hour() < 11)
+			if i > 0 {
+				assert.True(t, early[i-1].Low.Time.Before(l.Low.Time))
+			}
+		}
+	}
+
+	byExposure, err := prediction.PredictDaylightLows(0, tides.WithLowsSort(tides.LOWS_SORT_EXPOSURE))
+	if assert.NoError(t, err) {
+		assert.Len(t, byExposure, len(lows))
+		for i := 1; i < len(byExposure); i++ {
+			assert.GreaterOrEqual(t, byExposure[i-1].Exposure, byExposure[i].Exposure)
+		}
+	}
+
+	_, err = tides.ParseLowsSort("deepest")
+	assert.Error(t, err)
+}
+
+func TestDaylightLowsAcrossDaylightSaving(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// the clocks go forward on the 12th of March, and back on the 5th of November
+	for _, day := range []time.Time{time.Date(2023, 3, 12, 0, 0, 0, 0, loc), time.Date(2023, 11, 5, 0, 0, 0, 0, loc)} {
+		prediction := har.NewRangePrediction(day, day.AddDate(0, 0, 1), tides.WithDatum("MLLW"), tides.WithInterval(time.Minute*6), tides.WithLocation(loc))
+		lows, err := prediction.PredictDaylightLows(10, tides.WithLocalWindow(3*time.Hour, 23*time.Hour))
+		if !assert.NoError(t, err) || !assert.NotEmpty(t, lows, day) {
+			continue
+		}
+
+		// the window keeps to the local clock, rather than a fixed number of hours after midnight
+		for _, l := range lows {
+			assert.Equal(t, 3, l.WindowStart.Hour(), l.WindowStart)
+			assert.Equal(t, 0, l.WindowStart.Minute(), l.WindowStart)
+			assert.Equal(t, 23, l.WindowEnd.Hour(), l.WindowEnd)
+		}
+	}
+}
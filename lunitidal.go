@@ -0,0 +1,185 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+const (
+	// The mean interval between successive transits of the moon over a meridian (half a lunar day)
+	LUNAR_HALF_DAY = 12.4206012 * float64(time.Hour)
+)
+
+type (
+	// Lunitidal intervals & establishment, as published on NOAA datum sheets
+	LunitidalIntervals struct {
+		HighWaterInterval          time.Duration // HWI: mean time from the moon's local transit to the next high water
+		LowWaterInterval           time.Duration // LWI: mean time from the moon's local transit to the next low water
+		GreenwichHighWaterInterval time.Duration // mean time from the moon's Greenwich transit to the next high water
+		GreenwichLowWaterInterval  time.Duration // mean time from the moon's Greenwich transit to the next low water
+		CorrectedEstablishment     time.Duration // the high water interval implied by the M2 local epoch
+		HighWaterFullAndChange     time.Duration // HWF&C: the high water interval at new & full moon, from the M2 & S2 epochs
+		Highs                      int           // number of high waters averaged
+		Lows                       int           // number of low waters averaged
+	}
+)
+
+// Calculates the high & low water lunitidal intervals by pairing each high & low in the prediction with the preceding
+// lunar transit (upper or lower), along with the establishment of the port from the station's M2 (and S2) phases.
+// Intervals are averaged over the lunar half day, so the prediction should span at least a month, and preferably a
+// year or more. Requires the station's longitude.
+func (p *Prediction) LunitidalIntervals() (*LunitidalIntervals, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !p.Harmonics.HasLocation() {
+		return nil, fmt.Errorf("station has no latitude & longitude")
+	}
+	lon := p.Harmonics.Longitude
+
+	extrema := p.PredictExtrema()
+	if len(extrema) == 0 {
+		return nil, fmt.Errorf("no highs or lows in the prediction range")
+	}
+
+	// include the transits before the first extrema
+	start, end := p.Start.Add(-time.Duration(LUNAR_HALF_DAY)), p.End
+	local := astronomy.MoonTransitsBetween(start, end, lon)
+	greenwich := astronomy.MoonTransitsBetween(start, end, 0)
+
+	intervals := &LunitidalIntervals{}
+	var localHigh, localLow, greenwichHigh, greenwichLow []time.Duration
+	for _, ex := range extrema {
+		l, lok := intervalSinceTransit(local, ex.Time)
+		g, gok := intervalSinceTransit(greenwich, ex.Time)
+		if !lok || !gok {
+			continue
+		}
+		switch ex.Type {
+		case "H":
+			localHigh, greenwichHigh = append(localHigh, l), append(greenwichHigh, g)
+			intervals.Highs++
+		case "L":
+			localLow, greenwichLow = append(localLow, l), append(greenwichLow, g)
+			intervals.Lows++
+		}
+	}
+
+	intervals.HighWaterInterval = meanInterval(localHigh)
+	intervals.LowWaterInterval = meanInterval(localLow)
+	intervals.GreenwichHighWaterInterval = meanInterval(greenwichHigh)
+	intervals.GreenwichLowWaterInterval = meanInterval(greenwichLow)
+
+	err := p.establishment(intervals)
+	if err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
+
+// calculates the corrected establishment & HWF&C from the M2 & S2 Greenwich epochs
+func (p *Prediction) establishment(intervals *LunitidalIntervals) error {
+	var m2, s2 *HarmonicConstituent
+	for _, c := range p.Harmonics.Constituents {
+		switch c.Name {
+		case "M2":
+			m2 = c
+		case "S2":
+			s2 = c
+		}
+	}
+	if m2 == nil {
+		return fmt.Errorf("station has no M2 constituent")
+	}
+
+	// the local epoch; the moon crosses the local meridian 2λ/28.98 hours before it crosses Greenwich
+	kappaM2 := modulus(m2.PhaseUTC+2*p.Harmonics.Longitude, 360)
+
+	// subordinate stations are offset from the reference station high waters
+	var offset time.Duration
+	if p.Harmonics.TidePredOffsets != nil {
+		offset = time.Duration(p.Harmonics.TidePredOffsets.TimeOffsetHighTide) * time.Minute
+	}
+
+	m2Speed := constituentSpeed(m2, 28.9841042)
+	intervals.CorrectedEstablishment = wrapInterval(hoursToDuration(kappaM2/m2Speed) + offset)
+
+	// at new & full moon the sun & moon transit together, so the spring high water falls where M2 + S2 peaks
+	hwfc := kappaM2 / m2Speed
+	if s2 != nil {
+		kappaS2 := modulus(s2.PhaseUTC+2*p.Harmonics.Longitude, 360)
+		s2Speed := constituentSpeed(s2, 30)
+		level := func(t float64) float64 {
+			return m2.Amplitude*math.Cos(astronomy.DEG_TO_RAD*(m2Speed*t-kappaM2)) + s2.Amplitude*math.Cos(astronomy.DEG_TO_RAD*(s2Speed*t-kappaS2))
+		}
+		hwfc = maximizeNear(level, hwfc)
+	}
+	intervals.HighWaterFullAndChange = wrapInterval(hoursToDuration(hwfc) + offset)
+	return nil
+}
+
+// the time since the latest transit before t
+func intervalSinceTransit(transits []*astronomy.MoonTransit, t time.Time) (time.Duration, bool) {
+	var latest *astronomy.MoonTransit
+	for _, tr := range transits {
+		if tr.Time.After(t) {
+			break
+		}
+		latest = tr
+	}
+	if latest == nil {
+		return 0, false
+	}
+	return t.Sub(latest.Time), true
+}
+
+// averages intervals as angles around the lunar half day, so that intervals either side of a transit don't cancel out
+func meanInterval(intervals []time.Duration) time.Duration {
+	if len(intervals) == 0 {
+		return 0
+	}
+	var x, y float64
+	for _, i := range intervals {
+		angle := 2 * math.Pi * float64(i) / LUNAR_HALF_DAY
+		x += math.Cos(angle)
+		y += math.Sin(angle)
+	}
+	angle := modulus(math.Atan2(y, x), 2*math.Pi)
+	return time.Duration(angle / (2 * math.Pi) * LUNAR_HALF_DAY).Round(time.Second)
+}
+
+// wraps an interval into [0, lunar half day)
+func wrapInterval(d time.Duration) time.Duration {
+	return time.Duration(modulus(float64(d), LUNAR_HALF_DAY)).Round(time.Second)
+}
+
+// the speed of a constituent in degrees per hour, falling back to a nominal speed if the station omits it
+func constituentSpeed(c *HarmonicConstituent, nominal float64) float64 {
+	if c.Speed != 0 {
+		return c.Speed
+	}
+	return nominal
+}
+
+// finds the local maximum of f nearest t (in hours) by golden section search within two hours either side
+func maximizeNear(f func(float64) float64, t float64) float64 {
+	const ratio = 0.6180339887
+	a, b := t-2, t+2
+	for b-a > 1e-6 {
+		c, d := b-ratio*(b-a), a+ratio*(b-a)
+		if f(c) > f(d) {
+			b = d
+		} else {
+			a = c
+		}
+	}
+	return (a + b) / 2
+}
+
+func hoursToDuration(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour))
+}
@@ -0,0 +1,51 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLunitidalIntervals(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.AddDate(0, 2, 0), tides.WithInterval(time.Minute*6))
+
+	// local transits need the station longitude
+	_, err = prediction.LunitidalIntervals()
+	assert.Error(t, err)
+
+	har.Latitude, har.Longitude = 47.6026, -122.3393
+	li, err := prediction.LunitidalIntervals()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Seattle's high waters follow the moon's transit by about 4.4 hours, and its low waters by about 10.6
+	assert.InDelta(t, 4.38, li.HighWaterInterval.Hours(), 0.15)
+	assert.InDelta(t, 10.61, li.LowWaterInterval.Hours(), 0.15)
+	assert.Greater(t, li.Highs, 100)
+	assert.Greater(t, li.Lows, 100)
+
+	// the moon crosses Greenwich 122.34/14.49 hours before it crosses Seattle
+	lag := time.Duration(-har.Longitude / 14.492 * float64(time.Hour))
+	assert.InDelta(t, 0, wrapHalfDay(li.GreenwichHighWaterInterval-li.HighWaterInterval-lag).Minutes(), 5)
+	assert.InDelta(t, 0, wrapHalfDay(li.GreenwichLowWaterInterval-li.LowWaterInterval-lag).Minutes(), 5)
+
+	// the establishment from the M2 phase is close to the mean high water interval
+	assert.InDelta(t, li.HighWaterInterval.Hours(), li.CorrectedEstablishment.Hours(), 0.25)
+	assert.InDelta(t, li.CorrectedEstablishment.Hours(), li.HighWaterFullAndChange.Hours(), 0.5)
+}
+
+// wraps a difference in intervals into (-6.2h, 6.2h]
+func wrapHalfDay(d time.Duration) time.Duration {
+	half := tides.LUNAR_HALF_DAY
+	return time.Duration(math.Mod(math.Mod(float64(d)+half/2, half)+half, half) - half/2)
+}
@@ -0,0 +1,166 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// Node factors are recalculated at every step (the default)
+	NODAL_CONTINUOUS NodalCorrection = "continuous"
+	// Node factors are held fixed for each UTC day, evaluated at noon
+	NODAL_DAILY NodalCorrection = "daily"
+	// Node factors are held fixed for each calendar year, evaluated at mid-year, with the equilibrium
+	// arguments taken from the start of the year, as in NOAA & most published tables
+	NODAL_YEARLY NodalCorrection = "yearly"
+	// No node factors; f = 1 and u = 0
+	NODAL_NONE NodalCorrection = "none"
+)
+
+const (
+	// Schureman's formulas for f & u (the default), as used by NOAA
+	NODE_FACTORS_SCHUREMAN NodeFactorMethod = "schureman"
+	// Foreman's satellite constituent method, as used by the IOS tidal package, UTide and TICON, reduced to the
+	// nodal satellites: the perigee dependent ones are left out, so f & u approximate those packages' values.
+	// Satellites are tabulated for MM, MF, Q1, O1, K1, J1, OO1, 2N2, N2, NU2, M2, LAM2, K2 & M3, and compound
+	// constituents combine their members. M1 & L2, whose satellites depend on latitude, fall back to the Schureman
+	// formulas.
+	NODE_FACTORS_FOREMAN NodeFactorMethod = "foreman"
+)
+
+type (
+	// How often the node factors (f & u) are evaluated
+	NodalCorrection string
+
+	// The formulation used to calculate the node factors; constituents should be predicted with the same
+	// formulation that was used to analyze them
+	NodeFactorMethod string
+)
+
+// Parses a nodal correction policy name; empty means continuous
+func ParseNodalCorrection(s string) (NodalCorrection, error) {
+	switch NodalCorrection(strings.ToLower(strings.TrimSpace(s))) {
+	case "", NODAL_CONTINUOUS:
+		return NODAL_CONTINUOUS, nil
+	case NODAL_DAILY:
+		return NODAL_DAILY, nil
+	case NODAL_YEARLY:
+		return NODAL_YEARLY, nil
+	case NODAL_NONE:
+		return NODAL_NONE, nil
+	default:
+		return "", fmt.Errorf("unknown nodal correction: %s", s)
+	}
+}
+
+// Parses a node factor method name; empty means Schureman
+func ParseNodeFactorMethod(s string) (NodeFactorMethod, error) {
+	switch NodeFactorMethod(strings.ToLower(strings.TrimSpace(s))) {
+	case "", NODE_FACTORS_SCHUREMAN:
+		return NODE_FACTORS_SCHUREMAN, nil
+	case NODE_FACTORS_FOREMAN:
+		return NODE_FACTORS_FOREMAN, nil
+	default:
+		return "", fmt.Errorf("unknown node factor method: %s", s)
+	}
+}
+
+// Sets how often the node factors are evaluated. Accepts any spelling known to ParseNodalCorrection; unknown policies
+// are reported by Validate
+func WithNodalCorrection(policy NodalCorrection) PredictionOpt {
+	return func(p *Prediction) {
+		if parsed, err := ParseNodalCorrection(string(policy)); err == nil {
+			policy = parsed
+		}
+		p.NodalCorrection = policy
+	}
+}
+
+// Returns an error if the policy is not one of the supported policies; empty means continuous
+func (n NodalCorrection) Validate() error {
+	switch n {
+	case "", NODAL_CONTINUOUS, NODAL_DAILY, NODAL_YEARLY, NODAL_NONE:
+		return nil
+	default:
+		return fmt.Errorf("unknown nodal correction: %s", n)
+	}
+}
+
+// the time at which the node factors for t are evaluated
+func (n NodalCorrection) evaluationTime(t time.Time) time.Time {
+	t = t.UTC()
+	switch n {
+	case NODAL_DAILY:
+		return time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, time.UTC)
+	case NODAL_YEARLY:
+		start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start.Add(start.AddDate(1, 0, 0).Sub(start) / 2)
+	default:
+		return t
+	}
+}
+
+// calculates the equilibrium arguments at the start of a prediction; for the yearly policy these are taken from the
+// start of the year and advanced to the start of the prediction at each constituent's speed
+func (p *Prediction) harmonicResultsAt(constituents []*HarmonicConstituent, start time.Time) harmonicResults {
+	if p.NodalCorrection != NODAL_YEARLY {
+		return harmonicResultsAtTime(constituents, p.Astronomy.At(start))
+	}
+
+	yearStart := time.Date(start.UTC().Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	elapsedHours := start.Sub(yearStart).Hours()
+
+	results := harmonicResultsAtTime(constituents, p.Astronomy.At(yearStart))
+	for name, r := range results {
+		r.value = modulus(r.value+r.speed*elapsedHours, 2*math.Pi)
+		results[name] = r
+	}
+	return results
+}
+
+// calculates the node factors for each step of a range, evaluating each distinct time only once
+func (p *Prediction) harmonicFactorsForRange(constituents []*HarmonicConstituent, start, end time.Time) []harmonicFactors {
+	times := make([]time.Time, 0)
+	for t := start; t.Before(end); t = t.Add(p.Interval) {
+		times = append(times, t)
+	}
+	return p.harmonicFactorsAtTimes(constituents, times)
+}
+
+// calculates the node factors at each of a list of times, evaluating each distinct time only once
+func (p *Prediction) harmonicFactorsAtTimes(constituents []*HarmonicConstituent, times []time.Time) []harmonicFactors {
+	factors := make([]harmonicFactors, 0, len(times))
+	policy, method := p.NodalCorrection, p.Harmonics.NodeFactors
+
+	if policy == NODAL_NONE {
+		none := harmonicFactors{}
+		for _, c := range constituents {
+			none[c.Name] = harmonicFactor{node: 0, form: 1}
+		}
+		for range times {
+			factors = append(factors, none)
+		}
+		return factors
+	}
+
+	if policy != NODAL_DAILY && policy != NODAL_YEARLY {
+		for _, t := range times {
+			factors = append(factors, harmonicFactorsAtTime(constituents, p.Astronomy.At(t), method))
+		}
+		return factors
+	}
+
+	cache := map[time.Time]harmonicFactors{}
+	for _, t := range times {
+		at := policy.evaluationTime(t)
+		f, ok := cache[at]
+		if !ok {
+			f = harmonicFactorsAtTime(constituents, p.Astronomy.At(at), method)
+			cache[at] = f
+		}
+		factors = append(factors, f)
+	}
+	return factors
+}
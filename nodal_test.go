@@ -0,0 +1,154 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodalCorrectionPolicies(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	continuous := har.NewRangePrediction(start, end).Predict()
+
+	maxDiff := func(policy tides.NodalCorrection) float64 {
+		results := har.NewRangePrediction(start, end, tides.WithNodalCorrection(policy)).Predict()
+		assert.Equal(t, len(continuous), len(results))
+
+		var diff float64
+		for i := range results {
+			diff = math.Max(diff, math.Abs(results[i].Level-continuous[i].Level))
+		}
+		return diff
+	}
+
+	// the node factors change slowly, so holding them for a day makes almost no difference, and holding
+	// them for a year makes a small one; leaving them out entirely is a substantial error
+	assert.Less(t, maxDiff(tides.NODAL_CONTINUOUS), 1e-9)
+	assert.Less(t, maxDiff(tides.NODAL_DAILY), 0.001)
+	assert.Less(t, maxDiff(tides.NODAL_YEARLY), 0.03)
+	assert.Greater(t, maxDiff(tides.NODAL_YEARLY), 0.001)
+	assert.Greater(t, maxDiff(tides.NODAL_NONE), 0.05)
+}
+
+func TestNodalCorrectionYearlyIsStable(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// with fixed yearly factors, the level at a time doesn't depend on when the prediction starts
+	at := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	short := har.NewRangePrediction(at, at.Add(time.Hour), tides.WithNodalCorrection(tides.NODAL_YEARLY)).Predict()
+	long := har.NewRangePrediction(at.Add(-time.Hour*24*30), at.Add(time.Hour), tides.WithNodalCorrection(tides.NODAL_YEARLY)).Predict()
+
+	last := long[len(long)-len(short):]
+	for i := range short {
+		assert.Equal(t, short[i].Time, last[i].Time)
+		assert.InDelta(t, short[i].Level, last[i].Level, 1e-6)
+	}
+}
+
+func TestNodalCorrectionYearlyNOAA(t *testing.T) {
+	dir := t.TempDir()
+
+	// the node was at the vernal equinox in mid 2006, so NOAA's node factors for the middle of that year are the
+	// extremes of Schureman's formulas, & u is zero
+	published := map[string]float64{"M2": 0.963, "K1": 1.113, "O1": 1.183}
+	start := time.Date(2006, 12, 30, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 26)
+	for name, f := range published {
+		writeTestStation(t, dir, name, `{"harmonic_constituents":[{"name":"`+name+`","phase_UTC":0,"amplitude":1}]}`)
+		har, err := tides.LoadHarmonicsFromFile(dir, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		yearly := highest(har.NewRangePrediction(start, end, tides.WithNodalCorrection("Yearly")).Predict())
+		none := highest(har.NewRangePrediction(start, end, tides.WithNodalCorrection(tides.NODAL_NONE)).Predict())
+		assert.InDelta(t, f, yearly.Level, 0.001, name)
+		assert.LessOrEqual(t, absDuration(yearly.Time.Sub(none.Time)), time.Minute*2, name)
+	}
+}
+
+func highest(results []*tides.PredictionValue) *tides.PredictionValue {
+	var max *tides.PredictionValue
+	for _, r := range results {
+		if max == nil || r.Level > max.Level {
+			max = r
+		}
+	}
+	return max
+}
+
+func TestParseNodalCorrection(t *testing.T) {
+	policy, err := tides.ParseNodalCorrection("")
+	assert.NoError(t, err)
+	assert.Equal(t, tides.NODAL_CONTINUOUS, policy)
+
+	policy, err = tides.ParseNodalCorrection("Yearly")
+	assert.NoError(t, err)
+	assert.Equal(t, tides.NODAL_YEARLY, policy)
+
+	_, err = tides.ParseNodalCorrection("weekly")
+	assert.Error(t, err)
+
+	// the option accepts any spelling, & an unknown policy is reported rather than run as continuous
+	har := loadUncertaintyStation(t)
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour), tides.WithNodalCorrection(" Yearly"))
+	assert.Equal(t, tides.NODAL_YEARLY, prediction.NodalCorrection)
+	assert.NoError(t, prediction.Validate())
+
+	prediction = har.NewRangePrediction(start, start.Add(time.Hour), tides.WithNodalCorrection("weekly"))
+	assert.Error(t, prediction.Validate())
+	_, err = prediction.LevelAt(start)
+	assert.Error(t, err)
+}
+
+func TestNodeFactorMethod(t *testing.T) {
+	dir := t.TempDir()
+	constituents := `[
+		{"name": "M2", "amplitude": 1, "phase_UTC": 0},
+		{"name": "K1", "amplitude": 0.5, "phase_UTC": 30},
+		{"name": "O1", "amplitude": 0.3, "phase_UTC": 60}
+	]`
+	writeTestStation(t, dir, "schureman", `{"harmonic_constituents": `+constituents+`, "datums": []}`)
+	writeTestStation(t, dir, "foreman", `{"harmonic_constituents": `+constituents+`, "datums": [], "node_factors": "foreman"}`)
+	writeTestStation(t, dir, "bad", `{"harmonic_constituents": `+constituents+`, "datums": [], "node_factors": "darwin"}`)
+
+	schureman, err := tides.LoadHarmonicsFromFile(dir, "schureman")
+	assert.NoError(t, err)
+	assert.Equal(t, tides.NODE_FACTORS_SCHUREMAN, schureman.NodeFactors)
+
+	foreman, err := tides.LoadHarmonicsFromFile(dir, "foreman")
+	assert.NoError(t, err)
+	assert.Equal(t, tides.NODE_FACTORS_FOREMAN, foreman.NodeFactors)
+
+	_, err = tides.LoadHarmonicsFromFile(dir, "bad")
+	assert.Error(t, err)
+
+	// the formulations agree closely, but not exactly
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	a := schureman.NewRangePrediction(start, end).Predict()
+	b := foreman.NewRangePrediction(start, end).Predict()
+	assert.Equal(t, len(a), len(b))
+
+	var diff float64
+	for i := range a {
+		diff = math.Max(diff, math.Abs(a[i].Level-b[i].Level))
+	}
+	assert.Greater(t, diff, 1e-6)
+	assert.Less(t, diff, 0.02)
+}
@@ -0,0 +1,200 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// The residual falls linearly to zero at the end of the horizon
+	NOWCAST_DECAY_LINEAR NowcastDecay = "linear"
+	// The residual falls exponentially, with an e-folding time equal to the horizon
+	NOWCAST_DECAY_EXPONENTIAL NowcastDecay = "exponential"
+
+	DEFAULT_NOWCAST_HORIZON = 24 * time.Hour
+)
+
+type (
+	NowcastDecay string
+
+	// An observed water level, in the same datum & units as the prediction
+	Observation struct {
+		Time  time.Time
+		Level float64
+	}
+
+	// The difference between an observed level and the predicted level at the same time
+	Residual struct {
+		Time      time.Time
+		Observed  float64
+		Predicted float64
+		Residual  float64 // observed - predicted
+	}
+
+	// Settings for blending observations into a prediction
+	NowcastConfig struct {
+		Horizon         time.Duration // how long the latest residual takes to decay
+		Decay           NowcastDecay  // shape of the decay; defaults to linear
+		AveragingWindow time.Duration // residuals this far before the latest observation are averaged, to smooth out noise
+	}
+
+	NowcastResult struct {
+		Residuals    []*Residual        // residuals at each observation
+		Residual     float64            // the residual that is decayed into the forecast
+		ResidualTime time.Time          // time of the latest observation, when the decay starts
+		Levels       []*PredictionValue // blended levels for the prediction range
+		Extrema      []*PredictionValue // highs & lows of the blended levels
+	}
+
+	NowcastOpt func(*NowcastConfig)
+)
+
+// Sets how long the latest residual takes to decay toward zero
+func WithNowcastHorizon(horizon time.Duration) NowcastOpt {
+	return func(c *NowcastConfig) {
+		c.Horizon = horizon
+	}
+}
+
+// Sets the shape of the residual decay
+func WithNowcastDecay(decay NowcastDecay) NowcastOpt {
+	return func(c *NowcastConfig) {
+		c.Decay = decay
+	}
+}
+
+// Averages the residuals within this window before the latest observation
+func WithNowcastAveragingWindow(window time.Duration) NowcastOpt {
+	return func(c *NowcastConfig) {
+		c.AveragingWindow = window
+	}
+}
+
+// Blends recent observations into the prediction: residuals (observed - predicted) are calculated at each observation,
+// the blended levels follow the observations while they are available, and after the latest observation the residual
+// decays toward zero over the configured horizon. Extrema are recalculated from the blended levels.
+// Observations must be in the same datum & units as the prediction.
+func (p *Prediction) Nowcast(observations []*Observation, opts ...NowcastOpt) (*NowcastResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	config := &NowcastConfig{
+		Horizon: DEFAULT_NOWCAST_HORIZON,
+		Decay:   NOWCAST_DECAY_LINEAR,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("no observations provided")
+	}
+	if config.Horizon <= 0 {
+		return nil, fmt.Errorf("nowcast horizon must be positive")
+	}
+	if config.Decay != NOWCAST_DECAY_LINEAR && config.Decay != NOWCAST_DECAY_EXPONENTIAL {
+		return nil, fmt.Errorf("unknown nowcast decay: %s", config.Decay)
+	}
+
+	sorted := append([]*Observation{}, observations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	// step 1: predict over the observed period, and calculate the residuals
+	first, last := sorted[0].Time, sorted[len(sorted)-1].Time
+	observed := p.withRange(first, last.Add(p.Interval*2)).Predict()
+	if len(observed) < 2 {
+		return nil, fmt.Errorf("could not predict levels for the observation period")
+	}
+
+	result := &NowcastResult{
+		Residuals:    make([]*Residual, 0, len(sorted)),
+		ResidualTime: last,
+	}
+	for _, obs := range sorted {
+		predicted := interpolateLevel(observed, obs.Time)
+		result.Residuals = append(result.Residuals, &Residual{
+			Time:      obs.Time,
+			Observed:  obs.Level,
+			Predicted: predicted,
+			Residual:  obs.Level - predicted,
+		})
+	}
+
+	// step 2: pick the residual to carry forward, averaging over the window to smooth out noise
+	var sum float64
+	var count int
+	for _, r := range result.Residuals {
+		if !r.Time.Before(last.Add(-config.AveragingWindow)) {
+			sum += r.Residual
+			count++
+		}
+	}
+	result.Residual = sum / float64(count)
+
+	// step 3: blend the residuals into a prediction wide enough to find the extrema either side of the range
+	blended := make([]*PredictionValue, 0)
+	for _, v := range p.withRange(p.Start.Add(-24*time.Hour), p.End.Add(24*time.Hour)).Predict() {
+		blended = append(blended, &PredictionValue{
+			Time:  v.Time,
+			Level: v.Level + config.residualAt(v.Time, result),
+		})
+	}
+
+	// step 4: recalculate the extrema from the blended curve
+	extrema := p.getExtrema(blended, nil, nil)
+	p.annotateExtrema(extrema, blended)
+
+	result.Levels = p.finalize(filterPredictions(blended, p.Start, p.End))
+	result.Extrema = p.finalize(filterPredictions(extrema, p.Start, p.End))
+
+	return result, nil
+}
+
+// the correction to apply at a given time: the interpolated residual during the observed period,
+// and the decaying latest residual afterwards
+func (c *NowcastConfig) residualAt(t time.Time, result *NowcastResult) float64 {
+	residuals := result.Residuals
+	if t.Before(residuals[0].Time) {
+		return 0
+	}
+
+	if t.Before(result.ResidualTime) {
+		i := sort.Search(len(residuals), func(i int) bool { return residuals[i].Time.After(t) })
+		a, b := residuals[i-1], residuals[i]
+		frac := float64(t.Sub(a.Time)) / float64(b.Time.Sub(a.Time))
+		return a.Residual + frac*(b.Residual-a.Residual)
+	}
+
+	elapsed := float64(t.Sub(result.ResidualTime)) / float64(c.Horizon)
+	switch c.Decay {
+	case NOWCAST_DECAY_EXPONENTIAL:
+		return result.Residual * math.Exp(-elapsed)
+	default:
+		return result.Residual * math.Max(0, 1-elapsed)
+	}
+}
+
+// creates a copy of the prediction settings for a different range
+func (p *Prediction) withRange(start, end time.Time) *Prediction {
+	w := p.working()
+	w.Start, w.End = start, end
+	return w
+}
+
+// linearly interpolates the level at a time from a sorted series of predictions
+func interpolateLevel(values []*PredictionValue, t time.Time) float64 {
+	i := sort.Search(len(values), func(i int) bool { return values[i].Time.After(t) })
+	if i == 0 {
+		return values[0].Level
+	}
+	if i == len(values) {
+		return values[len(values)-1].Level
+	}
+
+	a, b := values[i-1], values[i]
+	frac := float64(t.Sub(a.Time)) / float64(b.Time.Sub(a.Time))
+	return a.Level + frac*(b.Level-a.Level)
+}
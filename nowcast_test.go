@@ -0,0 +1,74 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNowcast(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	obsStart := time.Date(2023, 4, 9, 18, 0, 0, 0, time.UTC)
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+
+	// observations run 0.3m above the prediction, every 6 minutes until the start of the range
+	predicted := har.NewRangePrediction(obsStart, start.Add(time.Minute), tides.WithInterval(time.Minute*6)).Predict()
+	observations := make([]*tides.Observation, 0)
+	for _, v := range predicted {
+		observations = append(observations, &tides.Observation{Time: v.Time, Level: v.Level + 0.3})
+	}
+
+	prediction := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10))
+	nowcast, err := prediction.Nowcast(observations, tides.WithNowcastHorizon(time.Hour*12))
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(observations), len(nowcast.Residuals))
+	for _, r := range nowcast.Residuals {
+		assert.InDelta(t, 0.3, r.Residual, VAL_TOLERANCE)
+	}
+	assert.InDelta(t, 0.3, nowcast.Residual, VAL_TOLERANCE)
+	assert.Equal(t, start, nowcast.ResidualTime)
+
+	// the residual decays linearly over 12 hours
+	astronomical := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10)).Predict()
+	assert.Equal(t, len(astronomical), len(nowcast.Levels))
+	for i, v := range nowcast.Levels {
+		elapsed := v.Time.Sub(start).Hours()
+		expected := 0.0
+		if elapsed < 12 {
+			expected = 0.3 * (1 - elapsed/12)
+		}
+		assert.InDelta(t, expected, v.Level-astronomical[i].Level, VAL_TOLERANCE)
+	}
+
+	// extrema come from the blended curve
+	extrema := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10)).PredictExtrema()
+	assert.Equal(t, len(extrema), len(nowcast.Extrema))
+	assert.Greater(t, nowcast.Extrema[0].Level, extrema[0].Level)
+	assert.InDelta(t, extrema[len(extrema)-1].Level, nowcast.Extrema[len(extrema)-1].Level, VAL_TOLERANCE)
+}
+
+func TestNowcastErrors(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour))
+
+	_, err = prediction.Nowcast(nil)
+	assert.Error(t, err)
+
+	_, err = prediction.Nowcast([]*tides.Observation{{Time: start, Level: 1}}, tides.WithNowcastDecay("sudden"))
+	assert.Error(t, err)
+}
@@ -1,5 +1,10 @@
 package tides
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type (
 	TidePredOffsets struct {
 		RefStationID         string  `json:"ref_station_id"`
@@ -9,3 +14,36 @@ type (
 		TimeOffsetLowTide    float64 `json:"time_offset_low_tide"`  // in minutes
 	}
 )
+
+type (
+	// Offsets for a subordinate current station, relative to a reference current station. Time offsets are
+	// applied to the corresponding reference events, and speed ratios to the flood and ebb speeds.
+	CurrentPredOffsets struct {
+		RefStationID             string   `json:"ref_station_id"`
+		TimeOffsetMinBeforeFlood float64  `json:"time_offset_min_before_flood"` // in minutes
+		TimeOffsetFlood          float64  `json:"time_offset_flood"`            // in minutes
+		TimeOffsetMinBeforeEbb   float64  `json:"time_offset_min_before_ebb"`   // in minutes
+		TimeOffsetEbb            float64  `json:"time_offset_ebb"`              // in minutes
+		SpeedRatioFlood          float64  `json:"speed_ratio_flood"`            // defaults to 1 when omitted
+		SpeedRatioEbb            float64  `json:"speed_ratio_ebb"`              // defaults to 1 when omitted
+		FloodDirection           *float64 `json:"flood_direction,omitempty"`    // degrees true; defaults to the reference station's
+		EbbDirection             *float64 `json:"ebb_direction,omitempty"`      // degrees true; defaults to the reference station's
+	}
+)
+
+// Decodes the offsets, defaulting omitted speed ratios to 1 so that a missing ratio leaves the speeds unchanged
+func (o *CurrentPredOffsets) UnmarshalJSON(data []byte) error {
+	type offsets CurrentPredOffsets
+	decoded := offsets{SpeedRatioFlood: 1, SpeedRatioEbb: 1}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	if decoded.SpeedRatioFlood <= 0 || decoded.SpeedRatioEbb <= 0 {
+		return fmt.Errorf("speed ratios must be positive, got flood %f & ebb %f", decoded.SpeedRatioFlood, decoded.SpeedRatioEbb)
+	}
+
+	*o = CurrentPredOffsets(decoded)
+	return nil
+}
@@ -18,18 +18,30 @@ import (
 )
 
 const (
-	METERS_TO_FEET   = 3.28084
 	PREDICTION_DATUM = "MTL"
 )
 
 type (
+	// The settings for a prediction. Its methods never modify it, so a Prediction may be shared between goroutines,
+	// and each call returns fresh results.
 	Prediction struct {
 		Start           time.Time
 		End             time.Time
 		Interval        time.Duration
 		Harmonics       *Harmonics
 		Datum           string
-		Units           string
+		Units           LengthUnit
+		SpeedUnits      SpeedUnit
+		Location        *time.Location         // if set, result times are reported in this location
+		Uncertainty     *UncertaintyConfig     // if set, results include uncertainty bands
+		Adjustment      *AtmosphericAdjustment // if set, applied to levels to estimate total water level
+		SeaLevelTrend   *SeaLevelTrend         // if set, overrides the station's sea level trend
+		Seasonal        *SeasonalMSL           // if set, the seasonal mean sea level anomaly is added to levels
+		NodalCorrection NodalCorrection        // how often node factors are evaluated; defaults to continuous
+		Astronomy       astronomy.Settings     // options for the astronomical arguments
+		Extrema         *ExtremaConfig         // if set, controls which turning points are reported as highs & lows
+		// the working state of a single calculation, only ever set on a working copy of the settings
+		datumOffset     float64 // resolved offset from PREDICTION_DATUM to Datum
 		extendedStart   time.Time
 		extendedEnd     time.Time
 		extendedResults []*PredictionValue // holds an expanded result set for working on
@@ -39,12 +51,18 @@ type (
 	PredictionValue struct {
 		Time        time.Time
 		Level       float64
-		Type        string // I = intermediate, H = high, L = low
+		Type        string            // I = intermediate, H = high, L = low
+		Class       string            // for extrema, HH, LH, HL or LL within the tidal day
+		Double      bool              // for extrema, one of a double high or low water
+		Stand       *Stand            // for extrema, the surrounding stand; only set when the ExtremaConfig has a StandRate
+		Uncertainty *LevelUncertainty // only set when the prediction has an UncertaintyConfig
 		lastExtrema *PredictionValue
 		nextExtrema *PredictionValue
 		// used to store uncorrected time/level prior to offsets being applied
 		uncTime  time.Time
 		uncLevel float64
+		// the non-tidal level included in Level
+		nonTidal float64
 	}
 )
 
@@ -55,13 +73,23 @@ func WithDatum(datum string) PredictionOpt {
 	}
 }
 
-// Sets the units on the Prediction
-func WithUnits(units string) PredictionOpt {
+// Sets the units on the Prediction. Accepts any spelling known to ParseLengthUnit; unknown units are reported by Validate
+func WithUnits(units LengthUnit) PredictionOpt {
 	return func(p *Prediction) {
+		if parsed, err := ParseLengthUnit(string(units)); err == nil {
+			units = parsed
+		}
 		p.Units = units
 	}
 }
 
+// Sets the location that result times are reported in
+func WithLocation(loc *time.Location) PredictionOpt {
+	return func(p *Prediction) {
+		p.Location = loc
+	}
+}
+
 // Sets the interval on the Prediction
 func WithInterval(interval time.Duration) PredictionOpt {
 	return func(p *Prediction) {
@@ -69,24 +97,108 @@ func WithInterval(interval time.Duration) PredictionOpt {
 	}
 }
 
+// Evaluates the astronomical arguments in Terrestrial Time, by applying Delta-T to the prediction times
+func WithDeltaT() PredictionOpt {
+	return func(p *Prediction) {
+		p.Astronomy.DeltaT = true
+	}
+}
+
+// Sets the series used for the mean lunar & solar elements. Accepts any spelling known to astronomy.ParseTheory;
+// unknown theories are reported by Validate
+func WithAstronomyTheory(theory astronomy.Theory) PredictionOpt {
+	return func(p *Prediction) {
+		if parsed, err := astronomy.ParseTheory(string(theory)); err == nil {
+			theory = parsed
+		}
+		p.Astronomy.Theory = theory
+	}
+}
+
+// Returns an error if the settings can't be predicted with, such as an unknown unit. Methods that return an error
+// check this first; the others log it & return nil.
+func (p *Prediction) Validate() error {
+	if err := p.Units.Validate(); err != nil {
+		return err
+	}
+	if err := p.SpeedUnits.Validate(); err != nil {
+		return err
+	}
+	if err := p.Astronomy.Theory.Validate(); err != nil {
+		return err
+	}
+	if err := p.NodalCorrection.Validate(); err != nil {
+		return err
+	}
+	if p.Uncertainty != nil {
+		if err := p.Uncertainty.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Seasonal != nil {
+		if err := p.Seasonal.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Calculates a prediction using the parameters provided in the Prediction
 func (p *Prediction) Predict() []*PredictionValue {
+	return p.working().predict()
+}
+
+// copies the settings, so that a calculation's working state is never shared with other calls
+func (p *Prediction) working() *Prediction {
+	w := *p
+	w.datumOffset = 0
+	w.extendedResults, w.extremaResults = nil, nil
+	return &w
+}
+
+// calculates a prediction; only called on a working copy
+func (p *Prediction) predict() []*PredictionValue {
+	if err := p.Validate(); err != nil {
+		log.Printf("! invalid prediction: %s", err)
+		return nil
+	}
+
+	// a prediction for a single point in time has an empty range, so evaluate the instant directly
+	if p.Start.Equal(p.End) {
+		level, err := p.LevelAt(p.Start)
+		if err != nil {
+			log.Fatalf("Error predicting level: %s", err.Error())
+		}
+		return p.finalize([]*PredictionValue{{Time: p.Start, Level: level}})
+	}
 
-	// resize start & end of bracket so that prior & next extrema are included
-	// we are liberal here, because we will trim the results later
+	// resize start & end of bracket so that prior & next extrema are included, along with the whole of the last
+	// tidal day; we are liberal here, because we will trim the results later
 	p.extendedStart = p.Start.Add(-24 * time.Hour)
-	p.extendedEnd = p.End.Add(24 * time.Hour)
+	p.extendedEnd = p.End.Add(26 * time.Hour)
+
+	// resolve the datum conversion once, rather than at every step
+	err := p.resolveDatumOffset()
+	if err != nil {
+		log.Fatalf("Error converting datum: %s", err.Error())
+	}
 
 	// step 1: calculate the tide results for our extended range; this should be wide enough
 	// to include the prior and next extrema, but we haven't identified those points yet
-	harmonicResults := harmonicResultsAtTime(p.Harmonics.Constituents, p.extendedStart)
-	harmonicFactors := harmonicFactorsForRange(p.Harmonics.Constituents, p.extendedStart, p.extendedEnd, p.Interval)
+	harmonicResults := p.harmonicResultsAt(p.Harmonics.Constituents, p.extendedStart)
+	harmonicFactors := p.harmonicFactorsForRange(p.Harmonics.Constituents, p.extendedStart, p.extendedEnd)
 
 	var i int
 	for t := p.extendedStart; t.Before(p.extendedEnd); t = t.Add(p.Interval) {
 		elapsedHours := t.Sub(p.extendedStart).Hours()
 		level := p.getLevel(elapsedHours, harmonicResults, harmonicFactors[i])
 
+		// at a reference station the non-tidal level is added before the extrema are picked, so it moves the highs &
+		// lows too; a subordinate station's offsets only apply to the tide, so there it is added after them (step 5)
+		if p.Harmonics.TidePredOffsets == nil {
+			level += p.nonTidalLevelAt(t)
+		}
+
 		p.extendedResults = append(p.extendedResults, &PredictionValue{
 			Time:  t,
 			Level: level,
@@ -115,9 +227,15 @@ func (p *Prediction) Predict() []*PredictionValue {
 	}
 	p.extendedResults = filterPredictions(p.extendedResults, priorExtrema.Time, nextExtrema.Time)
 
+	// optionally, propagate the constituent uncertainties into each result
+	if p.Uncertainty != nil {
+		p.calcUncertainty(harmonicResults, harmonicFactors)
+	}
+
 	// if this is a harmonic (reference) station, we are done
 	if p.Harmonics.TidePredOffsets == nil {
-		return filterPredictions(p.extendedResults, p.Start, p.End)
+		p.annotateExtrema(p.extremaResults, p.extendedResults)
+		return p.finalize(filterPredictions(p.extendedResults, p.Start, p.End))
 	}
 
 	// for subordinate stations...
@@ -127,17 +245,24 @@ func (p *Prediction) Predict() []*PredictionValue {
 		ex.uncTime = ex.Time
 		ex.uncLevel = ex.Level
 
+		ratio := 1.0
 		switch ex.Type {
 		case "H":
 			ex.Time = ex.Time.Add(time.Duration(p.Harmonics.TidePredOffsets.TimeOffsetHighTide) * time.Minute)
-			ex.Level *= p.Harmonics.TidePredOffsets.HeightOffsetHighTide
+			ratio = p.Harmonics.TidePredOffsets.HeightOffsetHighTide
 		case "L":
 			ex.Time = ex.Time.Add(time.Duration(p.Harmonics.TidePredOffsets.TimeOffsetLowTide) * time.Minute)
-			ex.Level *= p.Harmonics.TidePredOffsets.HeightOffsetLowTide
+			ratio = p.Harmonics.TidePredOffsets.HeightOffsetLowTide
+		}
+		ex.Level *= ratio
+		if ex.Uncertainty != nil {
+			ex.Uncertainty.scale(ratio)
 		}
 	}
 
-	// step 4: apply interpolated offsets to the intermediate points
+	// step 4: apply interpolated offsets to every intermediate point, not just the first: each point is moved to the
+	// same share of the time & range between the corrected extrema as it had between the uncorrected ones. Times are
+	// interpolated to the nanosecond, as LevelAt & PredictAt do, rather than truncated to whole minutes.
 	for _, result := range p.extendedResults {
 		if result.Type == "H" || result.Type == "L" {
 			continue
@@ -146,37 +271,56 @@ func (p *Prediction) Predict() []*PredictionValue {
 			continue
 		}
 
-		// calculate the proportion of time & value that our point represents between the uncorrected extrema
-		uncInterpTime := (result.Time.Sub(result.lastExtrema.uncTime).Minutes() / result.nextExtrema.uncTime.Sub(result.lastExtrema.uncTime).Minutes())
-		uncInterpLevel := (result.Level - result.lastExtrema.uncLevel) / (result.nextExtrema.uncLevel - result.lastExtrema.uncLevel)
-		//fmt.Printf("uncorrected interpolation: %f/%f\n", uncInterpTime, uncInterpLevel)
+		result.uncLevel = result.Level
+		result.uncTime = result.Time
+		result.Level = subordinateLevel(result.uncLevel, result.lastExtrema, result.nextExtrema)
+		result.Time = subordinateTime(result.uncTime, result.lastExtrema, result.nextExtrema)
+		if result.Uncertainty != nil {
+			result.Uncertainty.scale(subordinateRatio(result.lastExtrema, result.nextExtrema))
+		}
+	}
 
-		// uncInterpTime represents the proportion of time that our point represents between the uncorrected extrema
-		// so we can take this proportion and apply it to the corrected extrema to get the interpolated time
-		offsetTime := time.Duration(uncInterpTime*result.nextExtrema.Time.Sub(result.lastExtrema.Time).Minutes()) * time.Minute
-		interpolatedTime := result.lastExtrema.Time.Add(offsetTime)
-		//fmt.Printf("interpolated time: %s (%s/%s) %s\n", interpolatedTime, time.Duration(uncInterpTime*result.nextExtrema.uncTime.Sub(result.lastExtrema.uncTime).Minutes())*time.Minute, result.nextExtrema.uncTime.Sub(result.lastExtrema.uncTime), offsetTime)
+	// step 5: add the non-tidal level at each point's subordinate time
+	for _, result := range p.extendedResults {
+		if result.Type != "H" && result.Type != "L" {
+			p.addNonTidalLevel(result)
+		}
+	}
+	for _, ex := range p.extremaResults {
+		p.addNonTidalLevel(ex)
+	}
 
-		// uncInterpLevel represents the proportion of value that our point represents between the uncorrected extrema
-		// so we can take this proportion and apply it to the corrected extrema to get the interpolated level
-		offsetLevel := uncInterpLevel * (result.nextExtrema.Level - result.lastExtrema.Level)
-		interpolatedLevel := result.lastExtrema.Level + offsetLevel
-		//fmt.Printf("interpolated level: %f (%f/%f) %f\n", interpolatedLevel, uncInterpLevel, result.nextExtrema.Level-result.lastExtrema.Level, offsetLevel)
+	p.annotateExtrema(p.extremaResults, p.extendedResults)
+	return p.finalize(filterPredictions(p.extendedResults, p.Start, p.End))
+}
 
-		result.uncLevel = result.Level
-		result.uncTime = result.Time
-		result.Level = interpolatedLevel
-		result.Time = interpolatedTime
-		break
+// Resolves the conversion from the prediction datum (MTL) to the requested datum, including
+// the path through the datum graph. Returns nil if no conversion is needed.
+func (p *Prediction) DatumConversion() (*DatumConversion, error) {
+	if p.Datum == "" || strings.EqualFold(p.Datum, PREDICTION_DATUM) {
+		return nil, nil
 	}
+	return p.Harmonics.DatumConversion(PREDICTION_DATUM, p.Datum)
+}
 
-	return filterPredictions(p.extendedResults, p.Start, p.End)
+// resolves the offset from the prediction datum to the requested datum
+func (p *Prediction) resolveDatumOffset() error {
+	conv, err := p.DatumConversion()
+	if err != nil {
+		return err
+	}
+	p.datumOffset = 0
+	if conv != nil {
+		p.datumOffset = conv.Offset
+	}
+	return nil
 }
 
 // Calculates the extrema (highs & lows) using the parameters provided in the Prediction
 func (p *Prediction) PredictExtrema() []*PredictionValue {
-	p.Predict()
-	return filterPredictions(p.extremaResults, p.Start, p.End)
+	w := p.working()
+	w.predict()
+	return w.finalize(filterPredictions(w.extremaResults, w.Start, w.End))
 }
 
 // Same as PredictExtrema(), but only returns the lows
@@ -203,11 +347,54 @@ func (p *Prediction) PredictHighs() []*PredictionValue {
 	return results
 }
 
+// converts result times to the prediction's location, if one is set, and
+// centers any uncertainty bands on the (possibly offset) final levels
+func (p *Prediction) finalize(results []*PredictionValue) []*PredictionValue {
+	for _, r := range results {
+		if p.Location != nil {
+			r.Time = r.Time.In(p.Location)
+			if r.Stand != nil {
+				r.Stand.Start, r.Stand.End = r.Stand.Start.In(p.Location), r.Stand.End.In(p.Location)
+			}
+		}
+		if r.Uncertainty != nil {
+			r.Uncertainty.Lower = r.Level + r.Uncertainty.lowerDelta
+			r.Uncertainty.Upper = r.Level + r.Uncertainty.upperDelta
+		}
+	}
+	return results
+}
+
+// the non-tidal part of the level at t, in the prediction units: the change in mean sea level since the epoch, the
+// seasonal anomaly, and the meteorological adjustment, if any
+func (p *Prediction) nonTidalLevelAt(t time.Time) float64 {
+	level := p.seaLevelTrend().OffsetAt(t) + p.Seasonal.AnomalyAt(t)
+	if p.Adjustment != nil {
+		level += p.Adjustment.LevelAt(t)
+	}
+	return p.toOutputUnits(level)
+}
+
+func (p *Prediction) addNonTidalLevel(v *PredictionValue) {
+	v.nonTidal = p.nonTidalLevelAt(v.Time)
+	v.Level += v.nonTidal
+}
+
+// the level without its non-tidal part, i.e. for a subordinate station the level the offsets give
+func (v *PredictionValue) tidalLevel() float64 {
+	return v.Level - v.nonTidal
+}
+
 func (p *Prediction) getLevel(t float64, harmonicResults harmonicResults, harmonicFactors harmonicFactors) float64 {
+	return p.getLevelFor(p.Harmonics.Constituents, t, harmonicResults, harmonicFactors)
+}
+
+// sums the given constituents, and converts the result to the prediction datum & units
+func (p *Prediction) getLevelFor(constituents []*HarmonicConstituent, t float64, harmonicResults harmonicResults, harmonicFactors harmonicFactors) float64 {
 	amplitudes := make([]float64, 0)
 	result := 0.0
 
-	for _, constituent := range p.Harmonics.Constituents {
+	for _, constituent := range constituents {
 		_, amplitude, f, angle := calcConstituentParts(constituent, t, harmonicResults[constituent.Name], harmonicFactors[constituent.Name])
 		amplitudes = append(amplitudes, amplitude*f*math.Cos(angle))
 	}
@@ -216,24 +403,21 @@ func (p *Prediction) getLevel(t float64, harmonicResults harmonicResults, harmon
 		result += item
 	}
 
-	if p.Datum != "" && !strings.EqualFold(p.Datum, PREDICTION_DATUM) {
-		datum, err := p.Harmonics.DatumConvert(PREDICTION_DATUM, p.Datum, result)
-		if err != nil {
-			log.Fatalf("Error converting datum: %s", err.Error())
-		}
-		result = datum
-	}
+	result += p.datumOffset
 
-	if p.Units == "ft" {
-		result = result * METERS_TO_FEET
-	}
+	return p.Units.fromMeters(result)
+}
 
-	return result
+// the sea level trend in effect for the prediction, if any
+func (p *Prediction) seaLevelTrend() *SeaLevelTrend {
+	if p.SeaLevelTrend != nil {
+		return p.SeaLevelTrend
+	}
+	return p.Harmonics.SeaLevelTrend
 }
 
 func (p *Prediction) getExtrema(predictions []*PredictionValue, hResults harmonicResults, hFactors []harmonicFactors) (extrema []*PredictionValue) {
 	var isFalling bool
-	var lastExtrema *PredictionValue
 
 	// can't work on less than 2 points
 	if len(predictions) < 2 {
@@ -254,41 +438,39 @@ func (p *Prediction) getExtrema(predictions []*PredictionValue, hResults harmoni
 			if !isFalling {
 				p.Type = "H"
 				extrema = append(extrema, p)
-				lastExtrema = p
 			}
 			isFalling = true
 		} else {
 			if isFalling {
 				p.Type = "L"
 				extrema = append(extrema, p)
-				lastExtrema = p
 			}
 			isFalling = false
 		}
-
-		p.lastExtrema = lastExtrema
 	}
-	predictions[len(predictions)-1].lastExtrema = lastExtrema
 
-	// we now have all the extrema; walk the list
-	// again and set nextExtrema on each point
-	var i, extremaCursor int
-	for i < len(predictions) && extremaCursor < len(extrema) {
-		nextExtrema := extrema[extremaCursor]
-		p := predictions[i]
+	// optionally, drop insignificant extrema & find double highs & lows
+	extrema = p.filterExtrema(extrema)
 
-		// if we've passed the next extrema, advance to the next extrema
-		// without advancing the prediction and continue
-		if !p.Time.Before(nextExtrema.Time) {
-			extremaCursor++
-			continue
-		}
+	linkExtrema(predictions, extrema)
+	return
+}
 
-		p.nextExtrema = nextExtrema
-		i++
+// sets the last (at or before) & next (after) extrema on each point
+func linkExtrema(predictions []*PredictionValue, extrema []*PredictionValue) {
+	var cursor int
+	var lastExtrema *PredictionValue
+	for _, p := range predictions {
+		for cursor < len(extrema) && !p.Time.Before(extrema[cursor].Time) {
+			lastExtrema = extrema[cursor]
+			cursor++
+		}
+		p.lastExtrema = lastExtrema
+		p.nextExtrema = nil
+		if cursor < len(extrema) {
+			p.nextExtrema = extrema[cursor]
+		}
 	}
-
-	return
 }
 
 func modulus(a, b float64) float64 {
@@ -299,17 +481,9 @@ func modulus(a, b float64) float64 {
 	return result
 }
 
-func harmonicFactorsForRange(constituents []*HarmonicConstituent, start, end time.Time, interval time.Duration) []harmonicFactors {
-	factors := make([]harmonicFactors, 0)
-	for t := start; t.Before(end); t = t.Add(interval) {
-		factors = append(factors, harmonicFactorsAtTime(constituents, t))
-	}
-	return factors
-}
-
 func (p *Prediction) calculateMinDelta(t time.Time) float64 {
 	minDelta := math.MaxFloat64
-	astro := &astronomy.Astro{Time: t}
+	astro := p.Astronomy.At(t)
 
 	for _, c := range p.Harmonics.Constituents {
 		speed := c.Model.Speed(astro)
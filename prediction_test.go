@@ -10,6 +10,7 @@ import (
 
 	noaaTides "github.com/ryan-lang/noaa-tidesandcurrents/client/dataApi"
 	"github.com/ryan-lang/tides"
+	"github.com/ryan-lang/tides/astronomy"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,6 +79,8 @@ func TestGetHighLowPrediction(t *testing.T) {
 }
 
 func TestSubordinateGetHighLowPrediction(t *testing.T) {
+	skipWithoutStation(t, "9445719")
+
 	har, err := tides.LoadHarmonicsFromFile("./data", "9445719")
 	if err != nil {
 		t.Error(err)
@@ -112,6 +115,56 @@ func TestSubordinateGetHighLowPrediction(t *testing.T) {
 	}
 }
 
+func TestAstronomySettings(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// far in the future, delta-t is a few minutes, which shifts the tide by seconds; a millimeter or so of level
+	start := time.Date(2099, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+
+	base := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10)).Predict()
+	precise := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10), tides.WithDeltaT(),
+		tides.WithAstronomyTheory(astronomy.THEORY_MEEUS)).Predict()
+
+	levels := map[time.Time]float64{}
+	for _, r := range base {
+		levels[r.Time] = r.Level
+	}
+	var maxDiff float64
+	for _, r := range precise {
+		if level, ok := levels[r.Time]; ok {
+			maxDiff = math.Max(maxDiff, math.Abs(level-r.Level))
+		}
+	}
+	assert.Greater(t, maxDiff, 0.0001)
+	assert.Less(t, maxDiff, VAL_TOLERANCE)
+}
+
+func TestAstronomyTheoryValidated(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	// other spellings are normalized by the option
+	prediction := har.NewRangePrediction(start, end, tides.WithAstronomyTheory(astronomy.Theory("Meeus")))
+	assert.NoError(t, prediction.Validate())
+	assert.Equal(t, astronomy.THEORY_MEEUS, prediction.Astronomy.Theory)
+
+	// unknown theories are reported, rather than silently using the truncated series
+	prediction = har.NewRangePrediction(start, end, tides.WithAstronomyTheory(astronomy.Theory("vsop87")))
+	assert.Error(t, prediction.Validate())
+	_, err = prediction.LevelAt(start)
+	assert.Error(t, err)
+}
+
 func TestCompareWithNoaaHighLow(t *testing.T) {
 	testStations := []string{"9447130", "9413450", "9411340"}
 
@@ -171,6 +224,7 @@ func TestSubordinateCompareWithNoaaHighLow(t *testing.T) {
 	end := start.Add(time.Hour * 24)
 
 	for _, testStationID := range testStations {
+		skipWithoutStation(t, testStationID)
 
 		har, err := tides.LoadHarmonicsFromFile("./data", testStationID)
 		if err != nil {
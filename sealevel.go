@@ -0,0 +1,146 @@
+package tides
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// A relative sea level trend, applied to predictions as a time dependent offset to mean sea level.
+	// The offset is zero at the epoch midpoint, which is when the station datums & Z0 were observed.
+	SeaLevelTrend struct {
+		Rate          float64           `json:"rate"`                   // mm/yr
+		Acceleration  float64           `json:"acceleration,omitempty"` // mm/yr^2
+		EpochMidpoint float64           `json:"epoch_midpoint"`         // decimal year, e.g. 1992.5 for the 1983-2001 NTDE
+		Scenario      *SeaLevelScenario `json:"scenario,omitempty"`     // if set, used instead of the rate & acceleration
+	}
+
+	// A named sea level rise scenario curve, e.g. "Intermediate"
+	SeaLevelScenario struct {
+		Name   string                   `json:"name"`
+		Points []*SeaLevelScenarioPoint `json:"points"`
+	}
+
+	SeaLevelScenarioPoint struct {
+		Year   float64 `json:"year"`   // decimal year
+		Offset float64 `json:"offset"` // mm, relative to the epoch midpoint
+	}
+)
+
+// Sets the sea level trend on the Prediction, overriding any trend in the station data
+func WithSeaLevelTrend(trend *SeaLevelTrend) PredictionOpt {
+	return func(p *Prediction) {
+		trend.sortPoints()
+		p.SeaLevelTrend = trend
+	}
+}
+
+// Returns the change in mean sea level at a time, relative to the epoch midpoint, in meters
+func (s *SeaLevelTrend) OffsetAt(t time.Time) float64 {
+	if s == nil {
+		return 0
+	}
+
+	year := decimalYear(t)
+	if s.Scenario != nil && len(s.Scenario.Points) > 0 {
+		return s.Scenario.offsetAt(year) / 1000
+	}
+
+	elapsed := year - s.EpochMidpoint
+	return (s.Rate*elapsed + 0.5*s.Acceleration*elapsed*elapsed) / 1000
+}
+
+// interpolates the scenario offset (mm) at a decimal year; beyond the ends of the curve, the nearest point is used
+func (s *SeaLevelScenario) offsetAt(year float64) float64 {
+	points := s.Points
+	i := sort.Search(len(points), func(i int) bool { return points[i].Year > year })
+	if i == 0 {
+		return points[0].Offset
+	}
+	if i == len(points) {
+		return points[len(points)-1].Offset
+	}
+
+	a, b := points[i-1], points[i]
+	frac := (year - a.Year) / (b.Year - a.Year)
+	return a.Offset + frac*(b.Offset-a.Offset)
+}
+
+// Loads a named scenario from a CSV file. The first row is a header of "year" followed by scenario names, and each
+// following row is <year>,<offset mm>,... e.g.
+//
+//	year,Low,Intermediate,High
+//	2020,40,50,60
+func LoadSeaLevelScenarioFromFile(path, name string) (*SeaLevelScenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadSeaLevelScenarioCSV(f, name)
+}
+
+// Reads a named scenario from CSV. See LoadSeaLevelScenarioFromFile.
+func ReadSeaLevelScenarioCSV(r io.Reader, name string) (*SeaLevelScenario, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("scenario csv has no data")
+	}
+
+	col := -1
+	for i, h := range rows[0][1:] {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			col = i + 1
+			break
+		}
+	}
+	if col < 0 {
+		return nil, fmt.Errorf("scenario not found in csv: %s (available: %s)", name, strings.Join(rows[0][1:], ", "))
+	}
+
+	scenario := &SeaLevelScenario{
+		Name:   rows[0][col],
+		Points: make([]*SeaLevelScenarioPoint, 0, len(rows)-1),
+	}
+	for i, row := range rows[1:] {
+		year, yearErr := strconv.ParseFloat(strings.TrimSpace(row[0]), 64)
+		offset, offsetErr := strconv.ParseFloat(strings.TrimSpace(row[col]), 64)
+		if yearErr != nil || offsetErr != nil {
+			return nil, fmt.Errorf("scenario csv line %d: could not parse %v", i+2, row)
+		}
+		scenario.Points = append(scenario.Points, &SeaLevelScenarioPoint{Year: year, Offset: offset})
+	}
+
+	sort.Slice(scenario.Points, func(i, j int) bool { return scenario.Points[i].Year < scenario.Points[j].Year })
+	return scenario, nil
+}
+
+// sorts the scenario points by year, so they can be searched
+func (s *SeaLevelTrend) sortPoints() {
+	if s == nil || s.Scenario == nil {
+		return
+	}
+	points := s.Scenario.Points
+	sort.Slice(points, func(i, j int) bool { return points[i].Year < points[j].Year })
+}
+
+// the time as a fractional year, e.g. 2050.5 for the middle of 2050
+func decimalYear(t time.Time) float64 {
+	t = t.UTC()
+	start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	return float64(t.Year()) + float64(t.Sub(start))/float64(end.Sub(start))
+}
@@ -0,0 +1,103 @@
+package tides_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeaLevelTrend(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	start := time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+
+	// 3mm/yr for 57.5 years
+	trend := &tides.SeaLevelTrend{Rate: 3, EpochMidpoint: 1992.5}
+	assert.InDelta(t, 0.1725, trend.OffsetAt(start), 1e-6)
+
+	stationary := har.NewRangePrediction(start, end).PredictExtrema()
+	rising := har.NewRangePrediction(start, end, tides.WithSeaLevelTrend(trend)).PredictExtrema()
+
+	assert.Equal(t, len(stationary), len(rising))
+	for i := range rising {
+		assert.InDelta(t, stationary[i].Level+0.1725, rising[i].Level, VAL_TOLERANCE)
+	}
+
+	// acceleration adds 0.5*a*t^2
+	trend.Acceleration = 0.1
+	assert.InDelta(t, 0.1725+0.5*0.1*57.5*57.5/1000, trend.OffsetAt(start), 1e-6)
+}
+
+func TestSubordinateSeaLevelTrend(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	start := time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	trend := &tides.SeaLevelTrend{Rate: 3, EpochMidpoint: 1992.5}
+
+	// the rise is added after the offsets, so it isn't scaled by the height ratios (1.1 & 0.9)
+	opts := []tides.PredictionOpt{tides.WithInterval(time.Minute * 30)}
+	stationary := har.NewRangePrediction(start, end, opts...)
+	rising := har.NewRangePrediction(start, end, append(opts, tides.WithSeaLevelTrend(trend))...)
+
+	for _, predict := range []func(*tides.Prediction) []*tides.PredictionValue{(*tides.Prediction).Predict, (*tides.Prediction).PredictExtrema} {
+		stationaryResults, risingResults := predict(stationary), predict(rising)
+		if !assert.Equal(t, len(stationaryResults), len(risingResults)) {
+			return
+		}
+		for i, r := range risingResults {
+			assert.Equal(t, stationaryResults[i].Time, r.Time)
+			assert.InDelta(t, stationaryResults[i].Level+trend.OffsetAt(r.Time), r.Level, 1e-6, r.Time)
+		}
+	}
+
+	at := start.Add(time.Hour*7 + time.Minute*13)
+	stationaryLevel, err := stationary.LevelAt(at)
+	assert.NoError(t, err)
+	risingLevel, err := rising.LevelAt(at)
+	assert.NoError(t, err)
+	assert.InDelta(t, stationaryLevel+trend.OffsetAt(at), risingLevel, 1e-6)
+}
+
+func TestSeaLevelTrendFromStation(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "1", `{
+		"harmonic_constituents": [{"name": "M2", "amplitude": 1, "phase_UTC": 0}],
+		"datums": [{"name": "MTL", "value": 0}],
+		"sea_level_trend": {"rate": 10, "epoch_midpoint": 2000}
+	}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "1")
+	assert.NoError(t, err)
+
+	at := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	flat := &tides.SeaLevelTrend{}
+	withTrend := har.NewRangePrediction(at, at.Add(time.Hour)).Predict()
+	withoutTrend := har.NewRangePrediction(at, at.Add(time.Hour), tides.WithSeaLevelTrend(flat)).Predict()
+	assert.InDelta(t, withoutTrend[0].Level+1.0, withTrend[0].Level, VAL_TOLERANCE)
+}
+
+func TestSeaLevelScenario(t *testing.T) {
+	csv := "year,Low,Intermediate,High\n2100,300,1000,2000\n2050,150,400,600\n"
+
+	scenario, err := tides.ReadSeaLevelScenarioCSV(strings.NewReader(csv), "intermediate")
+	assert.NoError(t, err)
+	assert.Equal(t, "Intermediate", scenario.Name)
+
+	trend := &tides.SeaLevelTrend{Scenario: scenario}
+	at := func(year int) time.Time { return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC) }
+	assert.InDelta(t, 0.4, trend.OffsetAt(at(2050)), 1e-6)
+	assert.InDelta(t, 0.7, trend.OffsetAt(at(2075)), 1e-6)
+	assert.InDelta(t, 1.0, trend.OffsetAt(at(2150)), 1e-6)
+
+	_, err = tides.ReadSeaLevelScenarioCSV(strings.NewReader(csv), "Extreme")
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type (
+	// Seasonal deviations of monthly mean sea level from the annual mean. Either twelve monthly values (January
+	// first), which are interpolated between mid-month points, or a smooth curve of annual & semiannual terms.
+	// Stations that also carry SA and SSA constituents will count part of the cycle twice.
+	SeasonalMSL struct {
+		Monthly    []float64     `json:"monthly,omitempty"`
+		Annual     *SeasonalTerm `json:"annual,omitempty"`
+		SemiAnnual *SeasonalTerm `json:"semiannual,omitempty"`
+	}
+
+	// A sinusoidal seasonal term; the phase is the fraction of the cycle, in degrees, at which it peaks,
+	// measured from the start of the year
+	SeasonalTerm struct {
+		Amplitude float64 `json:"amplitude"`
+		Phase     float64 `json:"phase"`
+	}
+)
+
+// Adds the station's seasonal mean sea level anomaly to each level
+func WithSeasonalCorrection() PredictionOpt {
+	return func(p *Prediction) {
+		p.Seasonal = p.Harmonics.SeasonalMSL
+	}
+}
+
+// Adds the seasonal mean sea level anomaly from the given table to each level, instead of the station's. The table is
+// checked by Prediction.Validate.
+func WithSeasonalTable(seasonal *SeasonalMSL) PredictionOpt {
+	return func(p *Prediction) {
+		p.Seasonal = seasonal
+	}
+}
+
+// Checks that the table has twelve monthly values, or a seasonal curve
+func (s *SeasonalMSL) Validate() error {
+	if len(s.Monthly) == 0 && s.Annual == nil && s.SemiAnnual == nil {
+		return fmt.Errorf("seasonal msl needs monthly values or a seasonal curve")
+	}
+	if len(s.Monthly) != 0 && len(s.Monthly) != 12 {
+		return fmt.Errorf("seasonal msl needs 12 monthly values, got %d", len(s.Monthly))
+	}
+	return nil
+}
+
+// Returns the seasonal anomaly of mean sea level at a time, in meters
+func (s *SeasonalMSL) AnomalyAt(t time.Time) float64 {
+	if s == nil {
+		return 0
+	}
+
+	if len(s.Monthly) == 12 {
+		return s.monthlyAt(t.UTC())
+	}
+
+	cycle := 2 * math.Pi * (decimalYear(t) - math.Floor(decimalYear(t)))
+	var anomaly float64
+	if s.Annual != nil {
+		anomaly += s.Annual.Amplitude * math.Cos(cycle-s.Annual.Phase*math.Pi/180)
+	}
+	if s.SemiAnnual != nil {
+		anomaly += s.SemiAnnual.Amplitude * math.Cos(2*cycle-s.SemiAnnual.Phase*math.Pi/180)
+	}
+	return anomaly
+}
+
+// interpolates between the monthly values, each of which is taken to be at the middle of its month
+func (s *SeasonalMSL) monthlyAt(t time.Time) float64 {
+	month := t.Month()
+	mid := midMonth(t.Year(), month)
+
+	var a, b time.Time
+	var aVal, bVal float64
+	if t.Before(mid) {
+		prevYear, prevMonth := t.Year(), month-1
+		if prevMonth < time.January {
+			prevYear, prevMonth = prevYear-1, time.December
+		}
+		a, aVal = midMonth(prevYear, prevMonth), s.Monthly[prevMonth-1]
+		b, bVal = mid, s.Monthly[month-1]
+	} else {
+		nextYear, nextMonth := t.Year(), month+1
+		if nextMonth > time.December {
+			nextYear, nextMonth = nextYear+1, time.January
+		}
+		a, aVal = mid, s.Monthly[month-1]
+		b, bVal = midMonth(nextYear, nextMonth), s.Monthly[nextMonth-1]
+	}
+
+	frac := float64(t.Sub(a)) / float64(b.Sub(a))
+	return aVal + frac*(bVal-aVal)
+}
+
+// Computes a monthly seasonal table from observed levels, e.g. several years of hourly or monthly mean water levels.
+// Observations are averaged by calendar month, and the mean of the twelve monthly means is removed. The table is in
+// the units of the observations, which should be meters if it is used in a prediction.
+func ComputeSeasonalMSL(observations []*Observation) (*SeasonalMSL, error) {
+	var sums [12]float64
+	var counts [12]int
+	for _, obs := range observations {
+		m := obs.Time.UTC().Month() - 1
+		sums[m] += obs.Level
+		counts[m]++
+	}
+
+	monthly := make([]float64, 12)
+	var mean float64
+	for m := range monthly {
+		if counts[m] == 0 {
+			return nil, fmt.Errorf("no observations for %s", time.Month(m+1))
+		}
+		monthly[m] = sums[m] / float64(counts[m])
+		mean += monthly[m] / 12
+	}
+	for m := range monthly {
+		monthly[m] -= mean
+	}
+
+	return &SeasonalMSL{Monthly: monthly}, nil
+}
+
+func midMonth(year int, month time.Month) time.Time {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(start.AddDate(0, 1, 0).Sub(start) / 2)
+}
@@ -0,0 +1,119 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeasonalMonthly(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "1", `{
+		"units": "cm",
+		"harmonic_constituents": [{"name": "M2", "amplitude": 100, "phase_UTC": 0}],
+		"datums": [{"name": "MTL", "value": 0}],
+		"seasonal_msl": {"monthly": [-10, -8, -4, 0, 4, 8, 10, 8, 4, 0, -4, -8]}
+	}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "1")
+	assert.NoError(t, err)
+
+	// at mid-month the monthly value applies, in between it is interpolated, wrapping across the new year
+	s := har.SeasonalMSL
+	assert.InDelta(t, 0.10, s.AnomalyAt(time.Date(2023, 7, 16, 12, 0, 0, 0, time.UTC)), 1e-9)
+	assert.InDelta(t, -0.09, s.AnomalyAt(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), 1e-3)
+	assert.InDelta(t, -0.09, s.AnomalyAt(time.Date(2022, 12, 31, 23, 0, 0, 0, time.UTC)), 1e-3)
+
+	// the correction is opt in
+	at := time.Date(2023, 7, 16, 12, 0, 0, 0, time.UTC)
+	plain := har.NewRangePrediction(at, at.Add(time.Hour)).Predict()
+	corrected := har.NewRangePrediction(at, at.Add(time.Hour), tides.WithSeasonalCorrection()).Predict()
+	assert.InDelta(t, plain[0].Level+0.10, corrected[0].Level, VAL_TOLERANCE)
+}
+
+func TestSeasonalCurve(t *testing.T) {
+	s := &tides.SeasonalMSL{
+		Annual: &tides.SeasonalTerm{Amplitude: 0.1, Phase: 0},
+	}
+	assert.InDelta(t, 0.1, s.AnomalyAt(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), 1e-9)
+	assert.InDelta(t, -0.1, s.AnomalyAt(time.Date(2023, 7, 2, 12, 0, 0, 0, time.UTC)), 1e-3)
+
+	s.SemiAnnual = &tides.SeasonalTerm{Amplitude: 0.05, Phase: 180}
+	assert.InDelta(t, 0.05, s.AnomalyAt(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), 1e-9)
+}
+
+func TestSeasonalValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "1", `{
+		"harmonic_constituents": [{"name": "M2", "amplitude": 1, "phase_UTC": 0}],
+		"datums": [],
+		"seasonal_msl": {"monthly": [1, 2, 3]}
+	}`)
+
+	_, err := tides.LoadHarmonicsFromFile(dir, "1")
+	assert.Error(t, err)
+
+	// a table passed to the prediction is checked too, rather than adding nothing
+	har := loadTestSubordinate(t)
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	for _, table := range []*tides.SeasonalMSL{{}, {Monthly: []float64{0.1, 0.2}}} {
+		prediction := har.NewRangePrediction(start, start.Add(time.Hour), tides.WithSeasonalTable(table))
+		assert.Error(t, prediction.Validate())
+		assert.Nil(t, prediction.Predict())
+		_, err = prediction.LevelAt(start)
+		assert.Error(t, err)
+	}
+}
+
+func TestSubordinateSeasonal(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	table := &tides.SeasonalMSL{Annual: &tides.SeasonalTerm{Amplitude: 0.1, Phase: 180}}
+
+	// the anomaly is added after the offsets, so it isn't scaled by the height ratios (1.1 & 0.9)
+	opts := []tides.PredictionOpt{tides.WithInterval(time.Minute * 30)}
+	plain := har.NewRangePrediction(start, end, opts...)
+	seasonal := har.NewRangePrediction(start, end, append(opts, tides.WithSeasonalTable(table))...)
+
+	for _, predict := range []func(*tides.Prediction) []*tides.PredictionValue{(*tides.Prediction).Predict, (*tides.Prediction).PredictExtrema} {
+		plainResults, seasonalResults := predict(plain), predict(seasonal)
+		if !assert.Equal(t, len(plainResults), len(seasonalResults)) {
+			return
+		}
+		for i, r := range seasonalResults {
+			assert.Equal(t, plainResults[i].Time, r.Time)
+			assert.InDelta(t, plainResults[i].Level+table.AnomalyAt(r.Time), r.Level, 1e-6, r.Time)
+		}
+	}
+
+	at := start.Add(time.Hour*7 + time.Minute*13)
+	plainLevel, err := plain.LevelAt(at)
+	assert.NoError(t, err)
+	seasonalLevel, err := seasonal.LevelAt(at)
+	assert.NoError(t, err)
+	assert.InDelta(t, plainLevel+table.AnomalyAt(at), seasonalLevel, 1e-6)
+}
+
+func TestComputeSeasonalMSL(t *testing.T) {
+	// two years of daily observations, one meter higher in june
+	obs := make([]*tides.Observation, 0)
+	for d := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() < 2023; d = d.AddDate(0, 0, 1) {
+		level := 2.0
+		if d.Month() == time.June {
+			level = 3.0
+		}
+		obs = append(obs, &tides.Observation{Time: d, Level: level})
+	}
+
+	s, err := tides.ComputeSeasonalMSL(obs)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1-1.0/12, s.Monthly[5], 1e-9)
+	assert.InDelta(t, -1.0/12, s.Monthly[0], 1e-9)
+
+	_, err = tides.ComputeSeasonalMSL(obs[:100])
+	assert.Error(t, err)
+}
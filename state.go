@@ -0,0 +1,277 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// the step either side of an instant used to estimate the rate of change
+	stateRateStep = time.Minute
+)
+
+type (
+	// The state of the tide at an instant: the data behind a tide clock
+	TideState struct {
+		Time     time.Time
+		Level    float64
+		Rising   bool             // true when the next extrema is a high
+		Rate     float64          // rate of change of the level, in units per hour; positive when rising
+		Previous *PredictionValue // the last high or low at or before Time
+		Next     *PredictionValue // the next high or low after Time
+		Elapsed  float64          // fraction of the time from Previous to Next that has passed, 0-1
+		Cycle    float64          // position in the tidal cycle: 0 at high water, 0.5 at low water, approaching 1 at the next high
+	}
+)
+
+// Calculates the level at a single instant. For a reference station this evaluates the constituents at t alone; for a
+// subordinate station the offsets are interpolated between the surrounding highs & lows, so these are predicted too.
+func (p *Prediction) LevelAt(t time.Time) (float64, error) {
+	if p.Harmonics.TidePredOffsets == nil {
+		return p.harmonicLevelAt(t)
+	}
+
+	prev, next, err := p.extremaAround(t)
+	if err != nil {
+		return 0, err
+	}
+	return p.subordinateLevelAt(t, prev, next)
+}
+
+// Calculates the state of the tide at an instant: the level, whether & how fast it is rising or falling, the previous
+// & next highs & lows, and how far through the tidal cycle it is
+func (p *Prediction) StateAt(t time.Time) (*TideState, error) {
+	prev, next, err := p.extremaAround(t)
+	if err != nil {
+		return nil, err
+	}
+
+	levelAt := func(t time.Time) (float64, error) {
+		if p.Harmonics.TidePredOffsets == nil {
+			return p.harmonicLevelAt(t)
+		}
+		return p.subordinateLevelAt(t, prev, next)
+	}
+	level, err := levelAt(t)
+	if err != nil {
+		return nil, err
+	}
+	before, err := levelAt(t.Add(-stateRateStep))
+	if err != nil {
+		return nil, err
+	}
+	after, err := levelAt(t.Add(stateRateStep))
+	if err != nil {
+		return nil, err
+	}
+
+	state := &TideState{
+		Time:     t,
+		Level:    level,
+		Rising:   next.Type == "H",
+		Rate:     (after - before) / (2 * stateRateStep.Hours()),
+		Previous: prev,
+		Next:     next,
+		Elapsed:  float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time)),
+	}
+
+	// the falling half of the cycle runs from high to low, and the rising half from low to high
+	state.Cycle = state.Elapsed / 2
+	if state.Rising {
+		state.Cycle += 0.5
+	}
+
+	if p.Location != nil {
+		state.Time = state.Time.In(p.Location)
+	}
+	p.finalize([]*PredictionValue{prev, next})
+	return state, nil
+}
+
+// Calculates the levels at each of a sorted list of times, such as the times of irregular observations, without
+// predicting the levels in between. For subordinate stations, the highs & lows around the times are predicted so the
+// offsets can be interpolated. Results do not include uncertainty bands.
+func (p *Prediction) PredictAt(times []time.Time) ([]*PredictionValue, error) {
+	results := make([]*PredictionValue, 0, len(times))
+	if len(times) == 0 {
+		return results, nil
+	}
+	for i := 1; i < len(times); i++ {
+		if times[i].Before(times[i-1]) {
+			return nil, fmt.Errorf("times are not sorted: %s is before %s", times[i], times[i-1])
+		}
+	}
+
+	var levels []float64
+	var err error
+	if p.Harmonics.TidePredOffsets == nil {
+		levels, err = p.harmonicLevelsAt(times)
+	} else {
+		levels, err = p.subordinateLevelsAt(times)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range times {
+		results = append(results, &PredictionValue{Time: t, Level: levels[i]})
+	}
+	return p.finalize(results), nil
+}
+
+// evaluates the harmonic constituents at a single instant
+func (p *Prediction) harmonicLevelAt(t time.Time) (float64, error) {
+	levels, err := p.harmonicLevelsAt([]time.Time{t})
+	if err != nil {
+		return 0, err
+	}
+	return levels[0], nil
+}
+
+// evaluates the harmonic constituents at each of a list of times, which needn't be evenly spaced or sorted
+func (p *Prediction) harmonicLevelsAt(times []time.Time) ([]float64, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	w := p.working()
+	err := w.resolveDatumOffset()
+	if err != nil {
+		return nil, fmt.Errorf("error converting datum: %s", err)
+	}
+
+	// the equilibrium arguments are taken at the first time, & advanced from there at each constituent's speed
+	w.extendedStart = times[0]
+	constituents := w.Harmonics.Constituents
+	harmonicResults := w.harmonicResultsAt(constituents, times[0])
+	harmonicFactors := w.harmonicFactorsAtTimes(constituents, times)
+
+	levels := make([]float64, len(times))
+	for i, t := range times {
+		levels[i] = w.getLevel(t.Sub(times[0]).Hours(), harmonicResults, harmonicFactors[i])
+
+		// a subordinate station's non-tidal level is added at its own times, after the offsets
+		if w.Harmonics.TidePredOffsets == nil {
+			levels[i] += w.nonTidalLevelAt(t)
+		}
+	}
+	return levels, nil
+}
+
+// interpolates a subordinate level between corrected extrema
+func (p *Prediction) subordinateLevelAt(t time.Time, prev, next *PredictionValue) (float64, error) {
+	uncLevel, err := p.harmonicLevelAt(referenceTime(t, prev, next))
+	if err != nil {
+		return 0, err
+	}
+	return subordinateLevel(uncLevel, prev, next) + p.nonTidalLevelAt(t), nil
+}
+
+// interpolates subordinate levels at a sorted list of times, predicting the highs & lows only around the times
+func (p *Prediction) subordinateLevelsAt(times []time.Time) ([]float64, error) {
+	prevs := make([]*PredictionValue, len(times))
+	nexts := make([]*PredictionValue, len(times))
+	uncTimes := make([]time.Time, len(times))
+
+	// times less than a tidal day apart share a span of predicted extrema
+	window := time.Duration(TIDAL_DAY)
+	for i := 0; i < len(times); {
+		j := i + 1
+		for j < len(times) && times[j].Sub(times[j-1]) < window {
+			j++
+		}
+		extrema, err := p.extremaBetween(times[i], times[j-1])
+		if err != nil {
+			return nil, err
+		}
+		for k := i; k < j; k++ {
+			prevs[k], nexts[k] = bracketExtrema(extrema, times[k])
+			if prevs[k] == nil || nexts[k] == nil {
+				return nil, fmt.Errorf("no high or low within a tidal day of %s", times[k])
+			}
+			uncTimes[k] = referenceTime(times[k], prevs[k], nexts[k])
+		}
+		i = j
+	}
+
+	uncLevels, err := p.harmonicLevelsAt(uncTimes)
+	if err != nil {
+		return nil, err
+	}
+	levels := make([]float64, len(times))
+	for i := range times {
+		levels[i] = subordinateLevel(uncLevels[i], prevs[i], nexts[i]) + p.nonTidalLevelAt(times[i])
+	}
+	return levels, nil
+}
+
+// finds the last high or low at or before t, and the next after it
+func (p *Prediction) extremaAround(t time.Time) (prev, next *PredictionValue, err error) {
+	extrema, err := p.extremaBetween(t, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	prev, next = bracketExtrema(extrema, t)
+	if prev == nil || next == nil {
+		return nil, nil, fmt.Errorf("no high or low within a tidal day of %s", t)
+	}
+	return prev, next, nil
+}
+
+// predicts the highs & lows from a tidal day before start to a tidal day after end; a tidal day either side holds at
+// least one high & low, even for diurnal tides
+func (p *Prediction) extremaBetween(start, end time.Time) ([]*PredictionValue, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	_, err := p.DatumConversion()
+	if err != nil {
+		return nil, fmt.Errorf("error converting datum: %s", err)
+	}
+
+	// the window starts on a step of the prediction, so the extrema match those of the range prediction
+	window := time.Duration(TIDAL_DAY)
+	steps := math.Floor(float64(start.Add(-window).Sub(p.Start)) / float64(p.Interval))
+	windowStart := p.Start.Add(time.Duration(steps) * p.Interval)
+	return p.withRange(windowStart, end.Add(window)).PredictExtrema(), nil
+}
+
+// the last of the extrema at or before t, and the first after it
+func bracketExtrema(extrema []*PredictionValue, t time.Time) (prev, next *PredictionValue) {
+	for _, ex := range extrema {
+		if !ex.Time.After(t) {
+			prev = ex
+		} else if next == nil {
+			next = ex
+		}
+	}
+	return prev, next
+}
+
+// the reference station time for a subordinate time, the inverse of the mapping used for range predictions: t's share
+// of the interval between the corrected extrema is taken of the interval between the uncorrected extrema
+func referenceTime(t time.Time, prev, next *PredictionValue) time.Time {
+	interpTime := float64(t.Sub(prev.Time)) / float64(next.Time.Sub(prev.Time))
+	return prev.uncTime.Add(time.Duration(interpTime * float64(next.uncTime.Sub(prev.uncTime))))
+}
+
+// the subordinate time for a reference station time, the mapping used for range predictions: t's share of the
+// interval between the uncorrected extrema is taken of the interval between the corrected extrema
+func subordinateTime(t time.Time, prev, next *PredictionValue) time.Time {
+	interpTime := float64(t.Sub(prev.uncTime)) / float64(next.uncTime.Sub(prev.uncTime))
+	return prev.Time.Add(time.Duration(interpTime * float64(next.Time.Sub(prev.Time))))
+}
+
+// carries a reference level's share of the uncorrected range over to the corrected range, without the non-tidal level
+func subordinateLevel(uncLevel float64, prev, next *PredictionValue) float64 {
+	interpLevel := (uncLevel - prev.uncLevel) / (next.uncLevel - prev.uncLevel)
+	return prev.tidalLevel() + interpLevel*(next.tidalLevel()-prev.tidalLevel())
+}
+
+// the ratio of the corrected to the uncorrected range between two extrema, i.e. the height ratio that
+// subordinateLevel applies to a reference level between them
+func subordinateRatio(prev, next *PredictionValue) float64 {
+	return (next.tidalLevel() - prev.tidalLevel()) / (next.uncLevel - prev.uncLevel)
+}
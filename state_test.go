@@ -0,0 +1,243 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelAt(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// matches the range prediction at each step
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*6), tides.WithInterval(time.Minute*30), tides.WithDatum("MLLW"))
+	for _, r := range prediction.Predict() {
+		level, err := prediction.LevelAt(r.Time)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, r.Level, level, 1e-6, r.Time)
+		}
+	}
+
+	// a time prediction has a single result
+	at := start.Add(time.Hour * 2)
+	results := har.NewTimePrediction(at, tides.WithDatum("MLLW")).Predict()
+	if assert.Len(t, results, 1) {
+		level, err := prediction.LevelAt(at)
+		assert.NoError(t, err)
+		assert.Equal(t, at, results[0].Time)
+		assert.InDelta(t, level, results[0].Level, 1e-6)
+	}
+}
+
+func TestStateAt(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*6), tides.WithDatum("MLLW"))
+	extrema := prediction.PredictExtrema()
+
+	for at := start; at.Before(start.Add(time.Hour * 24)); at = at.Add(time.Minute * 97) {
+		state, err := prediction.StateAt(at)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.False(t, state.Previous.Time.After(at), at)
+		assert.True(t, state.Next.Time.After(at), at)
+		assert.NotEqual(t, state.Previous.Type, state.Next.Type, at)
+		assert.Equal(t, state.Next.Type == "H", state.Rising, at)
+		assert.Equal(t, state.Rising, state.Rate > 0, at)
+		assert.GreaterOrEqual(t, state.Elapsed, 0.0)
+		assert.Less(t, state.Elapsed, 1.0)
+		if state.Rising {
+			assert.GreaterOrEqual(t, state.Cycle, 0.5)
+		} else {
+			assert.Less(t, state.Cycle, 0.5)
+		}
+
+		// the level is between the surrounding extrema
+		assert.LessOrEqual(t, state.Level, state.Previous.Level+state.Next.Level-minLevel(state.Previous, state.Next)+1e-9)
+		assert.GreaterOrEqual(t, state.Level, minLevel(state.Previous, state.Next)-1e-9)
+
+		// and the extrema are those of the range prediction
+		for _, ex := range extrema {
+			if ex.Time.Equal(state.Next.Time) {
+				assert.InDelta(t, ex.Level, state.Next.Level, 1e-9)
+			}
+		}
+	}
+}
+
+func TestStateAtKeepsSettings(t *testing.T) {
+	har := loadUncertaintyStation(t)
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the highs & lows around the instant are predicted with all of the prediction's settings
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithUncertainty(0.95), tides.WithLocation(loc))
+	state, err := prediction.StateAt(start.Add(time.Hour * 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ex := range []*tides.PredictionValue{state.Previous, state.Next} {
+		assert.Equal(t, loc, ex.Time.Location())
+		if assert.NotNil(t, ex.Uncertainty) {
+			assert.InDelta(t, 0.02, ex.Uncertainty.StdDev, 0.001)
+		}
+	}
+}
+
+func TestStateAtSubordinate(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*6))
+	extrema := prediction.PredictExtrema()
+	if !assert.NotEmpty(t, extrema) {
+		return
+	}
+
+	// at each corrected extrema, the level is the corrected level, & the state brackets it
+	for _, ex := range extrema {
+		level, err := prediction.LevelAt(ex.Time)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, ex.Level, level, 1e-6, ex.Time)
+		}
+
+		state, err := prediction.StateAt(ex.Time.Add(time.Minute))
+		if assert.NoError(t, err) {
+			assert.Equal(t, ex.Time, state.Previous.Time)
+			assert.InDelta(t, ex.Level, state.Previous.Level, 1e-9)
+			assert.Equal(t, ex.Type == "L", state.Rising)
+		}
+	}
+}
+
+func minLevel(a, b *tides.PredictionValue) float64 {
+	if a.Level < b.Level {
+		return a.Level
+	}
+	return b.Level
+}
+
+func TestLevelAtSubordinateRange(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	// every point of a range prediction is offset, at the same interpolated time & level as a single instant
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*12), tides.WithInterval(time.Minute*30))
+	results := prediction.Predict()
+	if !assert.NotEmpty(t, results) {
+		return
+	}
+	for _, r := range results {
+		level, err := prediction.LevelAt(r.Time)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, level, r.Level, 1e-6, r.Time)
+		}
+	}
+}
+
+func TestSubordinateRangeOffsets(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	// the reference station's points at 03:30, 06:30 & 10:30. The baseline's step 4 stopped after the first
+	// intermediate point of the extended range, so these kept the reference times & levels; each is now moved
+	// between the corrected extrema, without truncating the interpolated time to whole minutes
+	cases := []struct {
+		baselineTime  time.Time
+		baselineLevel float64
+		time          time.Time
+		level         float64
+	}{
+		{time.Date(2023, 4, 10, 3, 30, 0, 0, time.UTC), 1.260665, time.Date(2023, 4, 10, 3, 23, 34, 285714285, time.UTC), 1.312044},
+		{time.Date(2023, 4, 10, 6, 30, 0, 0, time.UTC), 0.624222, time.Date(2023, 4, 10, 7, 2, 30, 0, time.UTC), 0.691458},
+		{time.Date(2023, 4, 10, 10, 30, 0, 0, time.UTC), 0.124009, time.Date(2023, 4, 10, 11, 8, 10, 909090909, time.UTC), 0.144901},
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	results := har.NewRangePrediction(start, start.Add(time.Hour*12), tides.WithInterval(time.Minute*30)).Predict()
+	for _, c := range cases {
+		var found bool
+		for _, r := range results {
+			if r.Time.Equal(c.time) {
+				found = true
+				assert.InDelta(t, c.level, r.Level, 1e-5, c.time)
+			}
+			if r.Time.Equal(c.baselineTime) {
+				assert.Greater(t, math.Abs(c.baselineLevel-r.Level), 0.01, c.baselineTime)
+			}
+		}
+		assert.True(t, found, c.time)
+	}
+}
+
+func TestPredictAt(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// irregular times, matching the range prediction where they fall on its steps
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*6), tides.WithDatum("MLLW"))
+	levels := map[time.Time]float64{}
+	for _, r := range prediction.Predict() {
+		levels[r.Time] = r.Level
+	}
+
+	times := []time.Time{start, start.Add(time.Minute * 7), start.Add(time.Minute * 12), start.Add(time.Hour*5 + time.Second*13), start.Add(time.Hour * 18)}
+	results, err := prediction.PredictAt(times)
+	if !assert.NoError(t, err) || !assert.Len(t, results, len(times)) {
+		return
+	}
+	for i, r := range results {
+		assert.Equal(t, times[i], r.Time)
+		if expected, ok := levels[r.Time]; ok {
+			assert.InDelta(t, expected, r.Level, 1e-6, r.Time)
+		}
+		level, err := prediction.LevelAt(r.Time)
+		assert.NoError(t, err)
+		assert.InDelta(t, level, r.Level, 1e-6, r.Time)
+	}
+
+	// times must be sorted
+	_, err = prediction.PredictAt([]time.Time{start.Add(time.Hour), start})
+	assert.Error(t, err)
+
+	results, err = prediction.PredictAt(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPredictAtSubordinate(t *testing.T) {
+	har := loadTestSubordinate(t)
+
+	// two clusters of times, days apart, are offset the same as single instants
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*6))
+	times := []time.Time{start.Add(time.Minute * 3), start.Add(time.Hour*2 + time.Minute*41), start.Add(time.Hour * 7), start.AddDate(0, 0, 5), start.AddDate(0, 0, 5).Add(time.Minute * 90)}
+	results, err := prediction.PredictAt(times)
+	if !assert.NoError(t, err) || !assert.Len(t, results, len(times)) {
+		return
+	}
+	for _, r := range results {
+		level, err := prediction.LevelAt(r.Time)
+		if assert.NoError(t, err) {
+			assert.InDelta(t, level, r.Level, 1e-6, r.Time)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tides
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+type (
+	// The tides, sun & moon for one local day at a station
+	DailySummary struct {
+		Start      time.Time                  // start of the day, in the prediction's location
+		Extrema    []*PredictionValue         // highs & lows during the day
+		SunAndMoon *astronomy.DayEvents       // sun & moon rise, set & transits; nil if the station has no position
+		Solunar    []*astronomy.SolunarPeriod // major & minor solunar periods; nil if the station has no position
+	}
+)
+
+// Calculates the highs & lows for each local day of the prediction, alongside sunrise, sunset, moonrise, moonset,
+// lunar transits and solunar periods when the station's latitude & longitude are known.
+// Days are taken in the prediction's location, or the station's timezone if none is set.
+func (p *Prediction) PredictDailySummaries() ([]*DailySummary, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	loc, err := p.localLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	extrema := p.PredictExtrema()
+	summaries := make([]*DailySummary, 0)
+	for day, end := DayBounds(p.Start, loc); day.Before(p.End); day, end = DayBounds(end, loc) {
+		summary := &DailySummary{
+			Start:   day,
+			Extrema: filterPredictions(extrema, day, end),
+		}
+		if p.Harmonics.HasLocation() {
+			summary.SunAndMoon = astronomy.Day(day, p.Harmonics.Latitude, p.Harmonics.Longitude)
+			summary.Solunar = summary.SunAndMoon.SolunarPeriods()
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// the location that local days are taken in: the prediction's, or else the station's
+func (p *Prediction) localLocation() (*time.Location, error) {
+	if p.Location != nil {
+		return p.Location, nil
+	}
+	loc, err := p.Harmonics.Location(TIME_MODE_LST_LDT, p.Start)
+	if err != nil {
+		return nil, fmt.Errorf("no location for local times: %s", err)
+	}
+	return loc, nil
+}
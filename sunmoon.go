@@ -0,0 +1,35 @@
+package tides
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+// Whether the station's latitude & longitude are known
+func (h *Harmonics) HasLocation() bool {
+	return h.Latitude != 0 || h.Longitude != 0
+}
+
+// Calculates sunrise, sunset, civil twilight, moonrise, moonset and moon illumination at the station for the calendar
+// day containing t, in the given location (see `Harmonics.Location`)
+func (h *Harmonics) SunAndMoon(t time.Time, loc *time.Location) (*astronomy.DayEvents, error) {
+	if !h.HasLocation() {
+		return nil, fmt.Errorf("station has no latitude & longitude")
+	}
+	return astronomy.Day(t.In(loc), h.Latitude, h.Longitude), nil
+}
+
+// Calculates the sun & moon events at the station for each calendar day of a range, in the given location
+func (h *Harmonics) SunAndMoonRange(start, end time.Time, loc *time.Location) ([]*astronomy.DayEvents, error) {
+	days := make([]*astronomy.DayEvents, 0)
+	for day, _ := DayBounds(start, loc); day.Before(end); _, day = DayBounds(day, loc) {
+		d, err := h.SunAndMoon(day, loc)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
@@ -0,0 +1,80 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSunAndMoon(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "seattle", `{"name":"Seattle, WA","latitude":47.6026,"longitude":-122.3393,"timezone":"America/Los_Angeles","harmonic_constituents":[{"name":"M2","phase_UTC":10.6,"amplitude":1.072}],"datums":[]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "seattle")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Seattle, WA", har.Name)
+	assert.True(t, har.HasLocation())
+
+	loc, err := har.Location(tides.TIME_MODE_LST_LDT, time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	day, err := har.SunAndMoon(time.Date(2024, 6, 21, 20, 0, 0, 0, time.UTC), loc)
+	if assert.NoError(t, err) && assert.NotNil(t, day.Sunrise) {
+		assert.Equal(t, time.Date(2024, 6, 21, 0, 0, 0, 0, loc), day.Start)
+		assert.Equal(t, 5, day.Sunrise.In(loc).Hour())
+	}
+
+	// one entry per local day
+	days, err := har.SunAndMoonRange(time.Date(2024, 3, 9, 0, 0, 0, 0, loc), time.Date(2024, 3, 12, 0, 0, 0, 0, loc), loc)
+	if assert.NoError(t, err) && assert.Len(t, days, 3) {
+		assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, loc), days[1].Start)
+		assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, loc), days[2].Start)
+	}
+
+	_, err = (&tides.Harmonics{}).SunAndMoon(time.Now(), time.UTC)
+	assert.Error(t, err)
+}
+
+func TestDailySummaries(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	start := time.Date(2024, 1, 24, 0, 0, 0, 0, loc)
+	prediction := har.NewRangePrediction(start, start.AddDate(0, 0, 3), tides.WithLocation(loc))
+
+	// without a position, only the tides are summarized
+	summaries, err := prediction.PredictDailySummaries()
+	if assert.NoError(t, err) && assert.Len(t, summaries, 3) {
+		assert.Nil(t, summaries[0].SunAndMoon)
+		assert.Nil(t, summaries[0].Solunar)
+	}
+
+	har.Latitude, har.Longitude = 47.6026, -122.3393
+	summaries, err = prediction.PredictDailySummaries()
+	if !assert.NoError(t, err) || !assert.Len(t, summaries, 3) {
+		return
+	}
+	for i, s := range summaries {
+		assert.Equal(t, start.AddDate(0, 0, i), s.Start)
+		assert.True(t, len(s.Extrema) >= 3 && len(s.Extrema) <= 4)
+		for _, ex := range s.Extrema {
+			assert.Equal(t, s.Start.Day(), ex.Time.Day())
+		}
+		if assert.NotNil(t, s.SunAndMoon) {
+			assert.NotNil(t, s.SunAndMoon.Sunrise)
+		}
+		assert.NotEmpty(t, s.Solunar)
+	}
+}
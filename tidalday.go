@@ -0,0 +1,87 @@
+package tides
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// The mean interval between successive upper transits of the moon
+	TIDAL_DAY = 2 * LUNAR_HALF_DAY
+
+	EXTREMA_HIGHER_HIGH = "HH"
+	EXTREMA_LOWER_HIGH  = "LH"
+	EXTREMA_HIGHER_LOW  = "HL"
+	EXTREMA_LOWER_LOW   = "LL"
+)
+
+// tidal days are counted from here, so they fall the same way whatever the range of the prediction
+var tidalDayEpoch = time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// Classifies each extrema as a higher high, lower high, higher low or lower low within its tidal day. Tidal days are
+// one lunar day long, starting midway between a high & low of M2, so that each holds two M2 highs & two M2 lows away
+// from its edges; of the two such mid-tides in each lunar day, the one an even number of M2 cycles from
+// tidalDayEpoch is used. Where a tidal day has only one high (or low), it is the higher high (or lower low).
+func (p *Prediction) classifyExtrema(extrema []*PredictionValue) {
+	if len(extrema) == 0 {
+		return
+	}
+	origin := p.tidalDayOrigin(extrema[0].Time)
+
+	days := map[int64][]*PredictionValue{}
+	for _, ex := range extrema {
+		day := int64(math.Floor(float64(ex.Time.Sub(origin)) / TIDAL_DAY))
+		days[day] = append(days[day], ex)
+	}
+
+	for _, day := range days {
+		var highest, lowest *PredictionValue
+		for _, ex := range day {
+			switch ex.Type {
+			case "H":
+				ex.Class = EXTREMA_LOWER_HIGH
+				if highest == nil || ex.Level > highest.Level {
+					highest = ex
+				}
+			case "L":
+				ex.Class = EXTREMA_HIGHER_LOW
+				if lowest == nil || ex.Level < lowest.Level {
+					lowest = ex
+				}
+			}
+		}
+		if highest != nil {
+			highest.Class = EXTREMA_HIGHER_HIGH
+		}
+		if lowest != nil {
+			lowest.Class = EXTREMA_LOWER_LOW
+		}
+	}
+}
+
+// the start of the tidal day around t: the first falling mid-tide of M2 after t, moved on by one M2 cycle if it is
+// an odd number of cycles from the epoch; without M2, the epoch itself
+func (p *Prediction) tidalDayOrigin(t time.Time) time.Time {
+	for _, c := range p.Harmonics.Constituents {
+		if c.Name != "M2" {
+			continue
+		}
+		m2 := []*HarmonicConstituent{c}
+		result := p.harmonicResultsAt(m2, t)[c.Name]
+		factor := harmonicFactorsAtTime(m2, p.Astronomy.At(t), p.Harmonics.NodeFactors)[c.Name]
+		speed, _, _, angle := calcConstituentParts(c, 0, result, factor)
+		if speed == 0 {
+			break
+		}
+		hours := modulus(math.Pi/2-angle, 2*math.Pi) / speed
+		origin := t.Add(time.Duration(hours * float64(time.Hour)))
+
+		// falling mid-tides come every half tidal day; pick the same alternate ones for every range
+		cycles := math.Round(float64(origin.Sub(tidalDayEpoch)) / LUNAR_HALF_DAY)
+		if math.Mod(cycles, 2) != 0 {
+			origin = origin.Add(time.Duration(LUNAR_HALF_DAY))
+		}
+		return origin
+	}
+	return tidalDayEpoch
+}
@@ -0,0 +1,114 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtremaClasses(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 1, 0), tides.WithInterval(time.Minute*6)).PredictExtrema()
+	if !assert.NotEmpty(t, extrema) {
+		return
+	}
+
+	counts := map[string]int{}
+	for i, ex := range extrema {
+		counts[ex.Class]++
+		switch ex.Type {
+		case "H":
+			assert.Contains(t, []string{tides.EXTREMA_HIGHER_HIGH, tides.EXTREMA_LOWER_HIGH}, ex.Class)
+		case "L":
+			assert.Contains(t, []string{tides.EXTREMA_HIGHER_LOW, tides.EXTREMA_LOWER_LOW}, ex.Class)
+		}
+
+		// Seattle is mixed semidiurnal, so the two highs of a tidal day are about 12 hours apart, and the
+		// higher high is the higher of the pair
+		if i >= 2 && ex.Type == "H" && ex.Class == tides.EXTREMA_LOWER_HIGH {
+			var paired *tides.PredictionValue
+			for _, other := range extrema {
+				if other != ex && other.Class == tides.EXTREMA_HIGHER_HIGH && absDuration(other.Time.Sub(ex.Time)) < 15*time.Hour {
+					paired = other
+				}
+			}
+			if assert.NotNil(t, paired, "no higher high near %s", ex.Time) {
+				assert.GreaterOrEqual(t, paired.Level, ex.Level)
+			}
+		}
+	}
+
+	// about one of each per tidal day
+	days := float64(30*24*time.Hour) / tides.TIDAL_DAY
+	for _, class := range []string{tides.EXTREMA_HIGHER_HIGH, tides.EXTREMA_LOWER_HIGH, tides.EXTREMA_HIGHER_LOW, tides.EXTREMA_LOWER_LOW} {
+		assert.InDelta(t, days, counts[class], 2, class)
+	}
+	assert.GreaterOrEqual(t, counts[tides.EXTREMA_HIGHER_HIGH], counts[tides.EXTREMA_LOWER_HIGH])
+	assert.GreaterOrEqual(t, counts[tides.EXTREMA_LOWER_LOW], counts[tides.EXTREMA_HIGHER_LOW])
+}
+
+func TestExtremaClassesDiurnal(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "diurnal", `{"harmonic_constituents":[
+		{"name":"O1","phase_UTC":100,"amplitude":0.6},
+		{"name":"M2","phase_UTC":80,"amplitude":0.05}
+	],"datums":[]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "diurnal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// O1 is slower than the tidal day, so there is at most one high & low in each, which is the higher high & lower low
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.AddDate(0, 0, 10), tides.WithInterval(time.Minute*6)).PredictExtrema()
+	if assert.NotEmpty(t, extrema) {
+		for _, ex := range extrema {
+			if ex.Type == "H" {
+				assert.Equal(t, tides.EXTREMA_HIGHER_HIGH, ex.Class, ex.Time)
+			} else {
+				assert.Equal(t, tides.EXTREMA_LOWER_LOW, ex.Class, ex.Time)
+			}
+		}
+	}
+}
+
+func TestExtremaClassesOverlappingRanges(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tidal days don't depend on where the range starts, so overlapping ranges agree on each extrema's class
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	classes := map[time.Time]string{}
+	for _, ex := range har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*6)).PredictExtrema() {
+		classes[ex.Time] = ex.Class
+	}
+
+	for _, shift := range []time.Duration{time.Hour * 6, time.Hour * 12, time.Hour * 15, time.Hour * 27} {
+		var compared int
+		for _, ex := range har.NewRangePrediction(start.Add(shift), end, tides.WithInterval(time.Minute*6)).PredictExtrema() {
+			if class, ok := classes[ex.Time]; ok {
+				assert.Equal(t, class, ex.Class, "%s with the start shifted by %s", ex.Time, shift)
+				compared++
+			}
+		}
+		assert.Greater(t, compared, 100)
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,128 @@
+package tides
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Local standard time & local daylight time, i.e. the clock time at the station (NOAA "lst_ldt")
+	TIME_MODE_LST_LDT TimeMode = "lst_ldt"
+	// Local standard time all year round, ignoring daylight saving (NOAA "lst")
+	TIME_MODE_LST TimeMode = "lst"
+
+	// Special zone names accepted by ResolveLocation
+	TZ_STATION = "station"
+	TZ_LOCAL   = "local"
+	TZ_UTC     = "utc"
+)
+
+type (
+	// Selects whether daylight saving time is observed when converting to a station's local time
+	TimeMode string
+)
+
+// Parses a time mode name (lst, lst_ldt), case-insensitively. An empty string is parsed as lst_ldt.
+func ParseTimeMode(s string) (TimeMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "lst_ldt", "lst/ldt":
+		return TIME_MODE_LST_LDT, nil
+	case "lst":
+		return TIME_MODE_LST, nil
+	default:
+		return "", fmt.Errorf("unknown time mode: %s", s)
+	}
+}
+
+// Returns the station's time zone, as declared in the station document. In LST mode, the returned location
+// is fixed at the zone's standard (non-daylight) offset in effect in the year of at.
+func (h *Harmonics) Location(mode TimeMode, at time.Time) (*time.Location, error) {
+	if h.Timezone == "" {
+		return nil, fmt.Errorf("station has no timezone")
+	}
+
+	loc, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("error loading station timezone (%s): %s", h.Timezone, err)
+	}
+
+	return applyTimeMode(loc, mode, at), nil
+}
+
+// Resolves a time zone name to a location. Accepts "station" (the station's declared timezone),
+// "local" (the timezone of this machine), "utc", or any IANA zone name (e.g. "Pacific/Honolulu"). In LST mode,
+// the standard offset is the one in effect in the year of at.
+func ResolveLocation(name string, h *Harmonics, mode TimeMode, at time.Time) (*time.Location, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case TZ_STATION:
+		if h == nil {
+			return nil, fmt.Errorf("station timezone requested, but no station provided")
+		}
+		return h.Location(mode, at)
+	case "", TZ_LOCAL:
+		return applyTimeMode(time.Local, mode, at), nil
+	case TZ_UTC, "gmt", "z":
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone: %s", name)
+	}
+
+	return applyTimeMode(loc, mode, at), nil
+}
+
+// Returns a fixed location at the standard (non-daylight) offset of the given location, as it was in the year of at.
+// Zones have changed their standard offsets over the years, so at should be within the times being converted.
+func StandardLocation(loc *time.Location, at time.Time) *time.Location {
+	// look at both solstices, since daylight time falls in different months in each hemisphere
+	year := at.In(loc).Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		t := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		if !t.IsDST() {
+			name, offset := t.Zone()
+			return time.FixedZone(name, offset)
+		}
+	}
+
+	name, offset := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	return time.FixedZone(name, offset)
+}
+
+// Returns the start (inclusive) and end (exclusive) of the calendar day containing t, in the given location.
+// Days are not always 24 hours long when daylight saving time starts or ends.
+func DayBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Returns the start (inclusive) and end (exclusive) of the calendar month containing t, in the given location
+func MonthBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// Creates a new Prediction covering the calendar day containing t, in the given location.
+// Results are reported in that location. Optionally accepts PredictionOpts
+func (h *Harmonics) NewDayPrediction(t time.Time, loc *time.Location, opts ...PredictionOpt) *Prediction {
+	start, end := DayBounds(t, loc)
+	return h.NewRangePrediction(start, end, append([]PredictionOpt{WithLocation(loc)}, opts...)...)
+}
+
+// Creates a new Prediction covering the calendar month containing t, in the given location.
+// Results are reported in that location. Optionally accepts PredictionOpts
+func (h *Harmonics) NewMonthPrediction(t time.Time, loc *time.Location, opts ...PredictionOpt) *Prediction {
+	start, end := MonthBounds(t, loc)
+	return h.NewRangePrediction(start, end, append([]PredictionOpt{WithLocation(loc)}, opts...)...)
+}
+
+func applyTimeMode(loc *time.Location, mode TimeMode, at time.Time) *time.Location {
+	if mode == TIME_MODE_LST {
+		return StandardLocation(loc, at)
+	}
+	return loc
+}
@@ -0,0 +1,90 @@
+package tides_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDayBoundsDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// DST starts on 2023-03-12, so the local day is 23 hours long
+	start, end := tides.DayBounds(time.Date(2023, 3, 12, 15, 0, 0, 0, time.UTC), loc)
+	assert.Equal(t, time.Date(2023, 3, 12, 5, 0, 0, 0, time.UTC), start.UTC())
+	assert.Equal(t, 23*time.Hour, end.Sub(start))
+
+	start, end = tides.MonthBounds(time.Date(2023, 3, 1, 2, 0, 0, 0, time.UTC), loc)
+	assert.Equal(t, time.Date(2023, 2, 1, 0, 0, 0, 0, loc), start)
+	assert.Equal(t, time.Date(2023, 3, 1, 0, 0, 0, 0, loc), end)
+}
+
+func TestResolveLocation(t *testing.T) {
+	har := &tides.Harmonics{Timezone: "America/Los_Angeles"}
+	at := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	loc, err := tides.ResolveLocation("station", har, tides.TIME_MODE_LST_LDT, at)
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	_, offset := at.In(loc).Zone()
+	assert.Equal(t, -7*60*60, offset)
+
+	// standard time ignores daylight saving
+	loc, err = tides.ResolveLocation("station", har, tides.TIME_MODE_LST, at)
+	assert.NoError(t, err)
+	_, offset = at.In(loc).Zone()
+	assert.Equal(t, -8*60*60, offset)
+
+	loc, err = tides.ResolveLocation("UTC", har, tides.TIME_MODE_LST, at)
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	_, err = tides.ResolveLocation("station", &tides.Harmonics{}, tides.TIME_MODE_LST_LDT, at)
+	assert.Error(t, err)
+
+	_, err = tides.ResolveLocation("Not/AZone", har, tides.TIME_MODE_LST_LDT, at)
+	assert.Error(t, err)
+}
+
+func TestStandardLocationYear(t *testing.T) {
+	loc, err := time.LoadLocation("America/Caracas")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// Venezuela's standard time was UTC-4:30 from 2007 to 2016, & UTC-4 before & after
+	at := time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC)
+	_, offset := at.In(tides.StandardLocation(loc, at)).Zone()
+	assert.Equal(t, -(4*60+30)*60, offset)
+
+	at = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	_, offset = at.In(tides.StandardLocation(loc, at)).Zone()
+	assert.Equal(t, -4*60*60, offset)
+}
+
+func TestDayPrediction(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	loc, err := time.LoadLocation("Pacific/Honolulu")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	prediction := har.NewDayPrediction(time.Date(2023, 4, 10, 6, 0, 0, 0, time.UTC), loc, tides.WithInterval(time.Hour))
+	results := prediction.Predict()
+
+	// 2023-04-10 06:00 UTC is still 2023-04-09 in Honolulu
+	assert.Equal(t, 24, len(results))
+	assert.Equal(t, time.Date(2023, 4, 9, 0, 0, 0, 0, loc), results[0].Time)
+	assert.Equal(t, loc, results[0].Time.Location())
+}
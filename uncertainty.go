@@ -0,0 +1,274 @@
+package tides
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ryan-lang/tides/astronomy"
+)
+
+const (
+	// First-order propagation of the constituent standard errors, assuming they are independent
+	UNCERTAINTY_ANALYTIC UncertaintyMethod = "analytic"
+	// Repeated predictions with amplitudes & phases drawn from normal distributions
+	UNCERTAINTY_MONTE_CARLO UncertaintyMethod = "monte_carlo"
+
+	DEFAULT_MONTE_CARLO_SAMPLES = 500
+)
+
+type (
+	UncertaintyMethod string
+
+	// Settings for propagating constituent uncertainties into the prediction
+	UncertaintyConfig struct {
+		Method     UncertaintyMethod // defaults to analytic
+		Confidence float64           // confidence level of the band, e.g. 0.95
+		Samples    int               // monte carlo only
+		Seed       int64             // monte carlo only
+	}
+
+	// The uncertainty of a predicted level, in the prediction's units
+	LevelUncertainty struct {
+		StdDev     float64       // standard deviation of the level
+		Lower      float64       // lower bound of the confidence band
+		Upper      float64       // upper bound of the confidence band
+		TimeStdDev time.Duration // standard deviation of the time of a high or low; zero for intermediate values
+
+		// band bounds relative to the level, so the band follows any subordinate offsets
+		lowerDelta float64
+		upperDelta float64
+	}
+)
+
+// Propagates the constituent amplitude & phase errors analytically into a confidence band (e.g. 0.95) on each result
+func WithUncertainty(confidence float64) PredictionOpt {
+	return func(p *Prediction) {
+		p.Uncertainty = &UncertaintyConfig{
+			Method:     UNCERTAINTY_ANALYTIC,
+			Confidence: confidence,
+		}
+	}
+}
+
+// Propagates the constituent amplitude & phase errors into a confidence band (e.g. 0.95) on each result, by running
+// the prediction repeatedly with randomly perturbed constituents. The seed makes the results repeatable. Samples of
+// zero or less use DEFAULT_MONTE_CARLO_SAMPLES. Each sample re-evaluates every step of the range, plus the day
+// either side of it that extrema are found in, so the cost is about samples times that of the prediction itself;
+// use a coarser interval for long ranges.
+func WithMonteCarloUncertainty(confidence float64, samples int, seed int64) PredictionOpt {
+	return func(p *Prediction) {
+		if samples <= 0 {
+			samples = DEFAULT_MONTE_CARLO_SAMPLES
+		}
+		p.Uncertainty = &UncertaintyConfig{
+			Method:     UNCERTAINTY_MONTE_CARLO,
+			Confidence: confidence,
+			Samples:    samples,
+			Seed:       seed,
+		}
+	}
+}
+
+// Returns an error if the confidence is not between 0 and 1, or a monte carlo config has no samples
+func (c *UncertaintyConfig) Validate() error {
+	if c.Confidence <= 0 || c.Confidence >= 1 {
+		return fmt.Errorf("uncertainty confidence must be between 0 and 1, got %f", c.Confidence)
+	}
+
+	switch c.Method {
+	case "", UNCERTAINTY_ANALYTIC:
+	case UNCERTAINTY_MONTE_CARLO:
+		if c.Samples <= 0 {
+			return fmt.Errorf("monte carlo uncertainty needs at least one sample, got %d", c.Samples)
+		}
+	default:
+		return fmt.Errorf("unknown uncertainty method: %s", c.Method)
+	}
+	return nil
+}
+
+// sets the Uncertainty on each of the extended results; must be called before offsets are applied
+func (p *Prediction) calcUncertainty(hResults harmonicResults, hFactors []harmonicFactors) {
+	switch p.Uncertainty.Method {
+	case UNCERTAINTY_MONTE_CARLO:
+		p.calcMonteCarloUncertainty(hResults, hFactors)
+	default:
+		p.calcAnalyticUncertainty(hResults, hFactors)
+	}
+}
+
+func (p *Prediction) calcAnalyticUncertainty(hResults harmonicResults, hFactors []harmonicFactors) {
+	z := math.Sqrt2 * math.Erfinv(p.Uncertainty.Confidence)
+
+	for _, r := range p.extendedResults {
+		elapsedHours := r.Time.Sub(p.extendedStart).Hours()
+		factors := hFactors[p.stepIndex(r.Time)]
+
+		// variance of the level, and for extrema the variance of its rate of change & its curvature, which
+		// give the variance of the time at which the rate of change is zero
+		var levelVar, rateVar, curvature float64
+		for _, c := range p.Harmonics.Constituents {
+			speed, amplitude, f, angle := calcConstituentParts(c, elapsedHours, hResults[c.Name], factors[c.Name])
+			phaseErr := c.PhaseError * astronomy.DEG_TO_RAD
+
+			levelVar += math.Pow(f*math.Cos(angle)*c.AmplitudeError, 2) + math.Pow(amplitude*f*math.Sin(angle)*phaseErr, 2)
+			rateVar += math.Pow(f*speed*math.Sin(angle)*c.AmplitudeError, 2) + math.Pow(amplitude*f*speed*math.Cos(angle)*phaseErr, 2)
+			curvature -= amplitude * f * speed * speed * math.Cos(angle)
+		}
+
+		stdDev := p.toOutputUnits(math.Sqrt(levelVar))
+		u := &LevelUncertainty{
+			StdDev:     stdDev,
+			lowerDelta: -z * stdDev,
+			upperDelta: z * stdDev,
+		}
+		if (r.Type == "H" || r.Type == "L") && curvature != 0 {
+			u.TimeStdDev = time.Duration(math.Sqrt(rateVar) / math.Abs(curvature) * float64(time.Hour))
+		}
+		r.Uncertainty = u
+	}
+}
+
+func (p *Prediction) calcMonteCarloUncertainty(hResults harmonicResults, hFactors []harmonicFactors) {
+	rng := rand.New(rand.NewSource(p.Uncertainty.Seed))
+	results := p.extendedResults
+	lowerQ := (1 - p.Uncertainty.Confidence) / 2
+	upperQ := 1 - lowerQ
+
+	// levels for each result, and the matching extrema times & levels, across all samples
+	levels := make([][]float64, len(results))
+	extremaTimes := map[*PredictionValue][]float64{}
+	extremaLevels := map[*PredictionValue][]float64{}
+
+	// the non-tidal level is the same in every sample, as it is in the predicted levels; a subordinate station's is
+	// only added after the offsets, so its samples are of the tide alone
+	nonTidal := make([]float64, len(results))
+	if p.Harmonics.TidePredOffsets == nil {
+		for j, r := range results {
+			nonTidal[j] = p.nonTidalLevelAt(r.Time)
+		}
+	}
+
+	for s := 0; s < p.Uncertainty.Samples; s++ {
+		constituents := perturbConstituents(p.Harmonics.Constituents, rng)
+
+		series := make([]*PredictionValue, len(results))
+		for j, r := range results {
+			elapsedHours := r.Time.Sub(p.extendedStart).Hours()
+			level := p.getLevelFor(constituents, elapsedHours, hResults, hFactors[p.stepIndex(r.Time)]) + nonTidal[j]
+			levels[j] = append(levels[j], level)
+			series[j] = &PredictionValue{Time: r.Time, Level: level}
+		}
+
+		// match each extrema to the nearest extrema of the same type in the sample
+		sampleExtrema := p.getExtrema(series, hResults, hFactors)
+		for _, r := range results {
+			if r.Type != "H" && r.Type != "L" {
+				continue
+			}
+			var match *PredictionValue
+			for _, ex := range sampleExtrema {
+				if ex.Type != r.Type || math.Abs(ex.Time.Sub(r.Time).Hours()) > 3 {
+					continue
+				}
+				if match == nil || absDuration(ex.Time.Sub(r.Time)) < absDuration(match.Time.Sub(r.Time)) {
+					match = ex
+				}
+			}
+			if match != nil {
+				extremaTimes[r] = append(extremaTimes[r], match.Time.Sub(r.Time).Hours())
+				extremaLevels[r] = append(extremaLevels[r], match.Level)
+			}
+		}
+	}
+
+	for j, r := range results {
+		samples := levels[j]
+		if len(extremaLevels[r]) > 1 {
+			samples = extremaLevels[r]
+		}
+
+		_, stdDev := meanAndStdDev(samples)
+		u := &LevelUncertainty{
+			StdDev:     stdDev,
+			lowerDelta: quantile(samples, lowerQ) - r.Level,
+			upperDelta: quantile(samples, upperQ) - r.Level,
+		}
+		if len(extremaTimes[r]) > 1 {
+			_, timeStdDev := meanAndStdDev(extremaTimes[r])
+			u.TimeStdDev = time.Duration(timeStdDev * float64(time.Hour))
+		}
+		r.Uncertainty = u
+	}
+}
+
+// scales the band & standard deviation by a subordinate station's height ratio
+func (u *LevelUncertainty) scale(ratio float64) {
+	u.StdDev *= math.Abs(ratio)
+	u.lowerDelta *= ratio
+	u.upperDelta *= ratio
+	if ratio < 0 {
+		u.lowerDelta, u.upperDelta = u.upperDelta, u.lowerDelta
+	}
+}
+
+// the index of the harmonic factors for a time in the extended range
+func (p *Prediction) stepIndex(t time.Time) int {
+	return int(math.Round(float64(t.Sub(p.extendedStart)) / float64(p.Interval)))
+}
+
+// converts a length in meters to the prediction units, without applying the datum
+func (p *Prediction) toOutputUnits(val float64) float64 {
+	return p.Units.fromMeters(val)
+}
+
+// copies the constituents, drawing each amplitude & phase from a normal distribution around the published value
+func perturbConstituents(constituents []*HarmonicConstituent, rng *rand.Rand) []*HarmonicConstituent {
+	perturbed := make([]*HarmonicConstituent, len(constituents))
+	for i, c := range constituents {
+		cp := *c
+		cp.Amplitude += rng.NormFloat64() * c.AmplitudeError
+		cp.PhaseUTC += rng.NormFloat64() * c.PhaseError
+		perturbed[i] = &cp
+	}
+	return perturbed
+}
+
+func meanAndStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum, sumSq float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	for _, v := range values {
+		sumSq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sumSq / float64(len(values)))
+}
+
+// linearly interpolated quantile, q in [0, 1]
+func quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,151 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyticUncertainty(t *testing.T) {
+	har := loadUncertaintyStation(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	extrema := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithUncertainty(0.95)).PredictExtrema()
+	assert.Equal(t, 4, len(extrema))
+
+	// at high & low water the level error is the amplitude error, and the
+	// time error is the phase error divided by the constituent speed
+	expectedTimeStdDev := 2.0 / 28.984104 * time.Hour.Seconds()
+	for _, ex := range extrema {
+		assert.NotNil(t, ex.Uncertainty)
+		assert.InDelta(t, 0.02, ex.Uncertainty.StdDev, 0.001)
+		assert.InDelta(t, ex.Level-1.96*ex.Uncertainty.StdDev, ex.Uncertainty.Lower, 0.001)
+		assert.InDelta(t, ex.Level+1.96*ex.Uncertainty.StdDev, ex.Uncertainty.Upper, 0.001)
+		assert.InDelta(t, expectedTimeStdDev, ex.Uncertainty.TimeStdDev.Seconds(), 5)
+	}
+
+	// halfway between, the level error comes from the phase error
+	results := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithUncertainty(0.95)).Predict()
+	for _, r := range results {
+		assert.NotNil(t, r.Uncertainty)
+		assert.LessOrEqual(t, r.Uncertainty.StdDev, math.Hypot(0.02, 2.0*math.Pi/180)+VAL_TOLERANCE)
+		if r.Type != "H" && r.Type != "L" {
+			assert.Equal(t, time.Duration(0), r.Uncertainty.TimeStdDev)
+		}
+	}
+}
+
+func TestMonteCarloUncertainty(t *testing.T) {
+	har := loadUncertaintyStation(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	opts := []tides.PredictionOpt{tides.WithInterval(time.Minute * 2)}
+	analytic := har.NewRangePrediction(start, start.Add(time.Hour*24), append(opts, tides.WithUncertainty(0.95))...).PredictExtrema()
+	monteCarlo := har.NewRangePrediction(start, start.Add(time.Hour*24), append(opts, tides.WithMonteCarloUncertainty(0.95, 400, 1))...).PredictExtrema()
+	assert.Equal(t, len(analytic), len(monteCarlo))
+
+	for i := range monteCarlo {
+		assert.InDelta(t, analytic[i].Uncertainty.StdDev, monteCarlo[i].Uncertainty.StdDev, 0.004)
+		assert.InDelta(t, analytic[i].Uncertainty.TimeStdDev.Minutes(), monteCarlo[i].Uncertainty.TimeStdDev.Minutes(), 1.0)
+		assert.Less(t, monteCarlo[i].Uncertainty.Lower, monteCarlo[i].Level)
+		assert.Greater(t, monteCarlo[i].Uncertainty.Upper, monteCarlo[i].Level)
+	}
+}
+
+func TestMonteCarloUncertaintyWithAdjustment(t *testing.T) {
+	har := loadUncertaintyStation(t)
+
+	// a steady low of 10hPa below the reference raises the water by 10cm
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour * 24)
+	adj := &tides.AtmosphericAdjustment{
+		Pressure: []*tides.PressureReading{
+			{Time: start.Add(-time.Hour * 48), Pressure: 1003.25},
+			{Time: end.Add(time.Hour * 48), Pressure: 1003.25},
+		},
+	}
+
+	opts := []tides.PredictionOpt{tides.WithInterval(time.Minute * 10), tides.WithMonteCarloUncertainty(0.95, 200, 1)}
+	plain := har.NewRangePrediction(start, end, opts...).Predict()
+	adjusted := har.NewRangePrediction(start, end, append(opts, tides.WithAtmosphericAdjustment(adj))...).Predict()
+	assert.Equal(t, len(plain), len(adjusted))
+
+	// the band moves with the level, rather than staying around the astronomical tide
+	for i := range adjusted {
+		assert.InDelta(t, plain[i].Level+0.1, adjusted[i].Level, VAL_TOLERANCE)
+		assert.InDelta(t, plain[i].Uncertainty.Lower+0.1, adjusted[i].Uncertainty.Lower, VAL_TOLERANCE)
+		assert.InDelta(t, plain[i].Uncertainty.Upper+0.1, adjusted[i].Uncertainty.Upper, VAL_TOLERANCE)
+		assert.Less(t, adjusted[i].Uncertainty.Lower, adjusted[i].Level)
+		assert.Greater(t, adjusted[i].Uncertainty.Upper, adjusted[i].Level)
+	}
+}
+
+func TestSubordinateUncertainty(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "errors", `{"harmonic_constituents":[{"name":"M2","phase_UTC":10.6,"amplitude":1.0,"amplitude_error":0.02,"phase_error":2.0}]}`)
+	writeTestStation(t, dir, "subordinate", `{"tide_pred_offsets":{"ref_station_id":"errors",
+		"height_offset_high_tide":2,"height_offset_low_tide":0.5,"time_offset_high_tide":0,"time_offset_low_tide":30},
+		"datums":[]}`)
+	har, err := tides.LoadHarmonicsFromFile(dir, "subordinate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the band is scaled by the height ratio, as the level is
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour*24), tides.WithInterval(time.Minute*10), tides.WithUncertainty(0.95))
+	for _, r := range prediction.Predict() {
+		if !assert.NotNil(t, r.Uncertainty) {
+			continue
+		}
+		switch r.Type {
+		case "H":
+			assert.InDelta(t, 0.04, r.Uncertainty.StdDev, 0.002)
+		case "L":
+			assert.InDelta(t, 0.01, r.Uncertainty.StdDev, 0.001)
+		default:
+			// between the extrema the ratio is that of the ranges, (2 + 0.5) / (1 + 1)
+			assert.LessOrEqual(t, r.Uncertainty.StdDev, 1.25*math.Hypot(0.02, 2.0*math.Pi/180)+VAL_TOLERANCE)
+			assert.GreaterOrEqual(t, r.Uncertainty.StdDev, 1.25*0.02-0.001)
+		}
+		assert.InDelta(t, r.Level-1.96*r.Uncertainty.StdDev, r.Uncertainty.Lower, 0.001)
+		assert.InDelta(t, r.Level+1.96*r.Uncertainty.StdDev, r.Uncertainty.Upper, 0.001)
+	}
+}
+
+func TestNoUncertainty(t *testing.T) {
+	har := loadUncertaintyStation(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	for _, r := range har.NewRangePrediction(start, start.Add(time.Hour)).Predict() {
+		assert.Nil(t, r.Uncertainty)
+	}
+}
+
+func TestInvalidUncertainty(t *testing.T) {
+	har := loadUncertaintyStation(t)
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	for _, opt := range []tides.PredictionOpt{
+		tides.WithUncertainty(0),
+		tides.WithUncertainty(1.5),
+		tides.WithMonteCarloUncertainty(95, 100, 1),
+	} {
+		prediction := har.NewRangePrediction(start, start.Add(time.Hour), opt)
+		assert.Error(t, prediction.Validate())
+		_, err := prediction.LevelAt(start)
+		assert.Error(t, err)
+	}
+
+	prediction := har.NewRangePrediction(start, start.Add(time.Hour))
+	prediction.Uncertainty = &tides.UncertaintyConfig{Method: tides.UNCERTAINTY_MONTE_CARLO, Confidence: 0.95}
+	assert.Error(t, prediction.Validate())
+
+	// the option fills in the default number of samples
+	prediction = har.NewRangePrediction(start, start.Add(time.Hour), tides.WithMonteCarloUncertainty(0.95, 0, 1))
+	assert.NoError(t, prediction.Validate())
+	assert.Equal(t, tides.DEFAULT_MONTE_CARLO_SAMPLES, prediction.Uncertainty.Samples)
+}
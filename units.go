@@ -0,0 +1,225 @@
+package tides
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	UNITS_METERS      LengthUnit = "m"
+	UNITS_CENTIMETERS LengthUnit = "cm"
+	UNITS_MILLIMETERS LengthUnit = "mm"
+	UNITS_FEET        LengthUnit = "ft"
+	UNITS_INCHES      LengthUnit = "in"
+
+	// Units used internally for all calculations
+	BASE_UNITS = UNITS_METERS
+)
+
+type (
+	// A unit of length used for station data, datums and prediction output.
+	// The zero value is treated as meters.
+	LengthUnit string
+)
+
+// number of meters in one of each unit
+var metersPerUnit = map[LengthUnit]float64{
+	UNITS_METERS:      1,
+	UNITS_CENTIMETERS: 0.01,
+	UNITS_MILLIMETERS: 0.001,
+	UNITS_FEET:        0.3048,
+	UNITS_INCHES:      0.0254,
+}
+
+// accepted spellings for each unit, matched case-insensitively
+var unitAliases = map[string]LengthUnit{
+	"m":           UNITS_METERS,
+	"meter":       UNITS_METERS,
+	"meters":      UNITS_METERS,
+	"metre":       UNITS_METERS,
+	"metres":      UNITS_METERS,
+	"metric":      UNITS_METERS,
+	"cm":          UNITS_CENTIMETERS,
+	"centimeter":  UNITS_CENTIMETERS,
+	"centimeters": UNITS_CENTIMETERS,
+	"centimetre":  UNITS_CENTIMETERS,
+	"centimetres": UNITS_CENTIMETERS,
+	"mm":          UNITS_MILLIMETERS,
+	"millimeter":  UNITS_MILLIMETERS,
+	"millimeters": UNITS_MILLIMETERS,
+	"millimetre":  UNITS_MILLIMETERS,
+	"millimetres": UNITS_MILLIMETERS,
+	"ft":          UNITS_FEET,
+	"foot":        UNITS_FEET,
+	"feet":        UNITS_FEET,
+	"english":     UNITS_FEET,
+	"in":          UNITS_INCHES,
+	"inch":        UNITS_INCHES,
+	"inches":      UNITS_INCHES,
+}
+
+// Parses a unit name (e.g. "m", "FT", "feet", "centimetres") into a LengthUnit.
+// An empty string is parsed as meters. Returns an error for unknown unit names.
+func ParseLengthUnit(s string) (LengthUnit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return BASE_UNITS, nil
+	}
+
+	u, ok := unitAliases[strings.ToLower(s)]
+	if !ok {
+		return "", fmt.Errorf("unknown length unit: %s", s)
+	}
+
+	return u, nil
+}
+
+// Returns an error if the unit is not one of the supported units
+func (u LengthUnit) Validate() error {
+	if _, ok := metersPerUnit[u.normalize()]; !ok {
+		return fmt.Errorf("unknown length unit: %s", u)
+	}
+	return nil
+}
+
+// Converts a value in this unit to meters
+func (u LengthUnit) ToMeters(val float64) (float64, error) {
+	factor, ok := metersPerUnit[u.normalize()]
+	if !ok {
+		return 0, fmt.Errorf("unknown length unit: %s", u)
+	}
+	return val * factor, nil
+}
+
+// Converts a value in meters to this unit
+func (u LengthUnit) FromMeters(val float64) (float64, error) {
+	factor, ok := metersPerUnit[u.normalize()]
+	if !ok {
+		return 0, fmt.Errorf("unknown length unit: %s", u)
+	}
+	return val / factor, nil
+}
+
+// converts a value in meters to this unit, which must already have been validated
+func (u LengthUnit) fromMeters(val float64) float64 {
+	return val / metersPerUnit[u.normalize()]
+}
+
+func (u LengthUnit) String() string {
+	return string(u.normalize())
+}
+
+// Converts a value from one unit to another
+func ConvertLength(val float64, from, to LengthUnit) (float64, error) {
+	m, err := from.ToMeters(val)
+	if err != nil {
+		return 0, err
+	}
+	return to.FromMeters(m)
+}
+
+// the zero value is meters
+func (u LengthUnit) normalize() LengthUnit {
+	if u == "" {
+		return BASE_UNITS
+	}
+	return u
+}
+
+const (
+	UNITS_METERS_PER_SECOND      SpeedUnit = "m/s"
+	UNITS_CENTIMETERS_PER_SECOND SpeedUnit = "cm/s"
+	UNITS_FEET_PER_SECOND        SpeedUnit = "ft/s"
+	UNITS_KNOTS                  SpeedUnit = "kn"
+
+	// Speed units used internally for all calculations
+	BASE_SPEED_UNITS = UNITS_METERS_PER_SECOND
+)
+
+type (
+	// A unit of speed used for tidal current data and predictions.
+	// The zero value is treated as meters per second.
+	SpeedUnit string
+)
+
+// number of meters per second in one of each unit
+var metersPerSecondPerUnit = map[SpeedUnit]float64{
+	UNITS_METERS_PER_SECOND:      1,
+	UNITS_CENTIMETERS_PER_SECOND: 0.01,
+	UNITS_FEET_PER_SECOND:        0.3048,
+	UNITS_KNOTS:                  1852.0 / 3600.0,
+}
+
+// accepted spellings for each speed unit, matched case-insensitively
+var speedUnitAliases = map[string]SpeedUnit{
+	"m/s":   UNITS_METERS_PER_SECOND,
+	"mps":   UNITS_METERS_PER_SECOND,
+	"cm/s":  UNITS_CENTIMETERS_PER_SECOND,
+	"cmps":  UNITS_CENTIMETERS_PER_SECOND,
+	"ft/s":  UNITS_FEET_PER_SECOND,
+	"fps":   UNITS_FEET_PER_SECOND,
+	"kn":    UNITS_KNOTS,
+	"kt":    UNITS_KNOTS,
+	"kts":   UNITS_KNOTS,
+	"knot":  UNITS_KNOTS,
+	"knots": UNITS_KNOTS,
+}
+
+// Parses a speed unit name (e.g. "m/s", "cm/s", "knots") into a SpeedUnit.
+// An empty string is parsed as meters per second. Returns an error for unknown unit names.
+func ParseSpeedUnit(s string) (SpeedUnit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return BASE_SPEED_UNITS, nil
+	}
+
+	u, ok := speedUnitAliases[strings.ToLower(s)]
+	if !ok {
+		return "", fmt.Errorf("unknown speed unit: %s", s)
+	}
+
+	return u, nil
+}
+
+// Returns an error if the unit is not one of the supported units
+func (u SpeedUnit) Validate() error {
+	if _, ok := metersPerSecondPerUnit[u.normalize()]; !ok {
+		return fmt.Errorf("unknown speed unit: %s", u)
+	}
+	return nil
+}
+
+// Converts a value in this unit to meters per second
+func (u SpeedUnit) ToMetersPerSecond(val float64) (float64, error) {
+	factor, ok := metersPerSecondPerUnit[u.normalize()]
+	if !ok {
+		return 0, fmt.Errorf("unknown speed unit: %s", u)
+	}
+	return val * factor, nil
+}
+
+// Converts a value in meters per second to this unit
+func (u SpeedUnit) FromMetersPerSecond(val float64) (float64, error) {
+	factor, ok := metersPerSecondPerUnit[u.normalize()]
+	if !ok {
+		return 0, fmt.Errorf("unknown speed unit: %s", u)
+	}
+	return val / factor, nil
+}
+
+// converts a value in meters per second to this unit, which must already have been validated
+func (u SpeedUnit) fromMetersPerSecond(val float64) float64 {
+	return val / metersPerSecondPerUnit[u.normalize()]
+}
+
+func (u SpeedUnit) String() string {
+	return string(u.normalize())
+}
+
+// the zero value is meters per second
+func (u SpeedUnit) normalize() SpeedUnit {
+	if u == "" {
+		return BASE_SPEED_UNITS
+	}
+	return u
+}
@@ -0,0 +1,119 @@
+package tides_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ryan-lang/tides"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLengthUnit(t *testing.T) {
+	testSet := []struct {
+		Input    string
+		Expected tides.LengthUnit
+	}{
+		{"", tides.UNITS_METERS},
+		{"m", tides.UNITS_METERS},
+		{"Metres", tides.UNITS_METERS},
+		{"cm", tides.UNITS_CENTIMETERS},
+		{"mm", tides.UNITS_MILLIMETERS},
+		{"FT", tides.UNITS_FEET},
+		{"feet", tides.UNITS_FEET},
+		{"in", tides.UNITS_INCHES},
+	}
+
+	for _, test := range testSet {
+		u, err := tides.ParseLengthUnit(test.Input)
+		assert.NoError(t, err)
+		assert.Equal(t, test.Expected, u, test.Input)
+	}
+
+	_, err := tides.ParseLengthUnit("furlongs")
+	assert.Error(t, err)
+}
+
+func TestConvertLength(t *testing.T) {
+	v, err := tides.ConvertLength(1, tides.UNITS_FEET, tides.UNITS_INCHES)
+	assert.NoError(t, err)
+	assert.InDelta(t, 12, v, 1e-9)
+
+	v, err = tides.ConvertLength(2.5, tides.UNITS_METERS, tides.UNITS_MILLIMETERS)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2500, v, 1e-9)
+
+	_, err = tides.ConvertLength(1, tides.LengthUnit("yd"), tides.UNITS_METERS)
+	assert.Error(t, err)
+}
+
+func TestPredictionUnits(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	meters := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10)).Predict()
+	feet := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10), tides.WithUnits(tides.UNITS_FEET)).Predict()
+	cm := har.NewRangePrediction(start, end, tides.WithInterval(time.Minute*10), tides.WithUnits(tides.UNITS_CENTIMETERS)).Predict()
+
+	assert.Equal(t, len(meters), len(feet))
+	assert.Equal(t, len(meters), len(cm))
+	for i := range meters {
+		assert.LessOrEqual(t, math.Abs(meters[i].Level/0.3048-feet[i].Level), VAL_TOLERANCE)
+		assert.LessOrEqual(t, math.Abs(meters[i].Level*100-cm[i].Level), VAL_TOLERANCE)
+	}
+}
+
+func TestPredictionUnitsValidated(t *testing.T) {
+	har, err := tides.LoadHarmonicsFromFile("./data", "9447130")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 4, 10, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	// other spellings are normalized by the option
+	upper := har.NewRangePrediction(start, end, tides.WithUnits(tides.LengthUnit("FT")), tides.WithSpeedUnits(tides.SpeedUnit("Knots")))
+	assert.NoError(t, upper.Validate())
+	assert.Equal(t, tides.UNITS_FEET, upper.Units)
+	assert.Equal(t, tides.UNITS_KNOTS, upper.SpeedUnits)
+	feet := har.NewRangePrediction(start, end, tides.WithUnits(tides.UNITS_FEET)).Predict()
+	assert.Equal(t, levels(feet), levels(upper.Predict()))
+
+	// unknown units are reported, rather than exiting
+	bad := har.NewRangePrediction(start, end, tides.WithUnits(tides.LengthUnit("furlongs")))
+	assert.Error(t, bad.Validate())
+	assert.Nil(t, bad.Predict())
+	_, err = bad.LevelAt(start)
+	assert.Error(t, err)
+
+	bad = har.NewRangePrediction(start, end, tides.WithSpeedUnits(tides.SpeedUnit("mph")))
+	assert.Error(t, bad.Validate())
+}
+
+func TestLoadStationUnits(t *testing.T) {
+	dir := t.TempDir()
+	writeTestStation(t, dir, "test", `{"units":"ft","harmonic_constituents":[{"name":"M2","phase_UTC":10.6,"amplitude":2}],"datums":[{"name":"MLLW","value":-3},{"name":"MTL","value":0}]}`)
+
+	har, err := tides.LoadHarmonicsFromFile(dir, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.InDelta(t, 2*0.3048, har.Constituents[0].Amplitude, 1e-9)
+	assert.InDelta(t, -3*0.3048, har.GetDatum("MLLW").Value, 1e-9)
+
+	ft, err := har.GetDatum("MLLW").ValueIn(tides.UNITS_FEET)
+	assert.NoError(t, err)
+	assert.InDelta(t, -3, ft, 1e-9)
+
+	writeTestStation(t, dir, "bad", `{"units":"furlongs","datums":[]}`)
+	_, err = tides.LoadHarmonicsFromFile(dir, "bad")
+	assert.Error(t, err)
+}